@@ -0,0 +1,15 @@
+// Command depbleed-vet runs depbleed as an analysis tool.
+//
+// It can either be run on its own or through `go vet`:
+//
+//	go vet -vettool=$(which depbleed-vet) ./...
+package main
+
+import (
+	depbleed "github.com/depbleed/go/go-depbleed"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(depbleed.Analyzer)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = cobra.Command{
+	Use:   "conflicts [directory]",
+	Short: "Reports leaks of the same type from different vendored copies",
+	Long: `Reports leaks of the same type from different vendored copies.
+
+The whole vendor tree of the specified directory is analyzed. When several
+packages leak the same upstream type from different vendored copies, values
+returned by one of them cannot be used with the others.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "./..."
+
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		if !filepath.IsAbs(path) && !strings.HasPrefix(path, ".") {
+			return fmt.Errorf("\"%s\" is not a directory", path)
+		}
+
+		wd, err := os.Getwd()
+
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %s", err)
+		}
+
+		gopath := build.Default.GOPATH
+
+		config, err := getConfig(path)
+
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+
+		module, err := depbleed.FindModule(strings.TrimSuffix(path, "..."))
+
+		if err != nil {
+			return fmt.Errorf("could not find module: %s", err)
+		}
+
+		group := patternGroup{patterns: []string{path}}
+
+		// Modules have a single, flat, vendor directory: only GOPATH projects
+		// can vendor several copies of a package.
+		if module != nil {
+			group.dir = module.Dir
+		} else {
+			vendoredPackagePaths, err := depbleed.GetVendoredPackagePaths(gopath, path)
+
+			if err != nil {
+				return fmt.Errorf("could not get vendored package paths: %s", err)
+			}
+
+			group.patterns = append(group.patterns, vendoredPackagePaths...)
+		}
+
+		var options []depbleed.Option
+
+		if config != nil {
+			options = append(options, depbleed.ConfigOption(*config))
+		}
+
+		if goVersion != "" {
+			options = append(options, depbleed.GoVersionOption(goVersion))
+		}
+
+		if len(allow) > 0 {
+			options = append(options, depbleed.AllowPackagesOption(allow...))
+		}
+
+		packageInfos, err := group.load(options...)
+
+		if err != nil {
+			return err
+		}
+
+		conflicts := depbleed.FindConflicts(packageInfos)
+
+		for _, conflict := range conflicts {
+			fmt.Fprintf(os.Stderr, "%s is leaked from %d different copies:\n", conflict.Type, len(conflict.Copies))
+
+			for _, leak := range conflict.Leaks {
+				fmt.Fprintf(os.Stderr, "\t%s:%d:%d: %s\n", relativePath(wd, leak.Position.Filename), leak.Position.Line, leak.Position.Column, leak)
+			}
+		}
+
+		if !noFail && len(conflicts) != 0 {
+			return LintingError{}
+		}
+
+		return nil
+	},
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(&conflictsCmd)
+}
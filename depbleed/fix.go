@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	wrap  bool
+	write bool
+)
+
+var fixCmd = cobra.Command{
+	Use:   "fix [packages]",
+	Short: "Fixes leaks automatically",
+	Long: `Fixes leaks automatically.
+
+With --wrap, the leaked named types are replaced by local types in the exported
+declarations of the leaking packages: an interface of the methods the package
+uses, or a type defined from the leaked type if it uses none or if the leaked
+values don't have them all. Local types that the package doesn't compile with,
+alone or along with the others, are skipped.
+
+The changes are printed as a unified diff, unless -w is specified. They must be
+reviewed, as the code that uses the leaked types may need to be adapted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !wrap {
+			return errors.New("no fix specified: use --wrap")
+		}
+
+		patterns := args
+
+		if len(patterns) == 0 {
+			patterns = []string{"."}
+		}
+
+		wd, err := os.Getwd()
+
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %s", err)
+		}
+
+		cmd.SilenceUsage = true
+
+		groups, files, err := groupPatterns(wd, patterns)
+
+		if err != nil {
+			return err
+		}
+
+		var options []depbleed.Option
+
+		if goVersion != "" {
+			options = append(options, depbleed.GoVersionOption(goVersion))
+		}
+
+		if len(allow) > 0 {
+			options = append(options, depbleed.AllowPackagesOption(allow...))
+		}
+
+		contents := map[string][]byte{}
+
+		for _, group := range groups {
+			packageInfos, err := group.load(options...)
+
+			if err != nil {
+				return err
+			}
+
+			for _, packageInfo := range packageInfos {
+				var leaks depbleed.Leaks
+
+				for _, leak := range packageInfo.Leaks() {
+					if files.contains(leak.Position.Filename) {
+						leaks = append(leaks, leak)
+					}
+				}
+
+				// The fixes are checked together before they are printed: the
+				// ones that break the package along with the others are skipped.
+				wrappers, errs := packageInfo.CombineWrappers(packageInfo.GetWrappers(leaks))
+
+				for _, err := range errs {
+					fmt.Fprintf(os.Stderr, "skipping a fix of package \"%s\": %s\n", packageInfo.Package.Path(), err)
+				}
+
+				var edits []analysis.TextEdit
+
+				for _, wrapper := range wrappers {
+					edits = append(edits, wrapper.Edits...)
+				}
+
+				packageContents, err := packageInfo.ApplyEdits(edits)
+
+				if err != nil {
+					return fmt.Errorf("could not fix package \"%s\": %s", packageInfo.Package.Path(), err)
+				}
+
+				for filename, content := range packageContents {
+					contents[filename] = content
+				}
+			}
+		}
+
+		var filenames []string
+
+		for filename := range contents {
+			filenames = append(filenames, filename)
+		}
+
+		sort.Strings(filenames)
+
+		for _, filename := range filenames {
+			if write {
+				info, err := os.Stat(filename)
+
+				if err != nil {
+					return fmt.Errorf("could not write \"%s\": %s", filename, err)
+				}
+
+				if err := ioutil.WriteFile(filename, contents[filename], info.Mode()); err != nil {
+					return fmt.Errorf("could not write \"%s\": %s", filename, err)
+				}
+
+				continue
+			}
+
+			old, err := ioutil.ReadFile(filename)
+
+			if err != nil {
+				return fmt.Errorf("could not read \"%s\": %s", filename, err)
+			}
+
+			name := relativePath(wd, filename)
+
+			fmt.Print(depbleed.UnifiedDiff("a/"+name, "b/"+name, string(old), string(contents[filename])))
+		}
+
+		return nil
+	},
+	SilenceErrors: true,
+}
+
+func init() {
+	fixCmd.Flags().BoolVar(&wrap, "wrap", false, "Replace the leaked named types by local types")
+	fixCmd.Flags().BoolVarP(&write, "write", "w", false, "Write the fixes to the files instead of printing a diff")
+
+	rootCmd.AddCommand(&fixCmd)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+	"github.com/depbleed/go/go-depbleed/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = cobra.Command{
+	Use:   "lsp",
+	Short: "Runs a language server that reports leaks as diagnostics",
+	Long: `Runs a language server that reports leaks as diagnostics.
+
+The server speaks the Language Server Protocol over the standard input and
+output. It publishes the leaks of the package of a document when the document
+is opened or saved, analyzing its unsaved content.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server := &lsp.Server{}
+
+		if configPath != "" {
+			config, err := depbleed.ReadConfig(configPath)
+
+			if err != nil {
+				return err
+			}
+
+			server.Config = config
+		}
+
+		if goVersion != "" {
+			server.Options = append(server.Options, depbleed.GoVersionOption(goVersion))
+		}
+
+		if len(allow) > 0 {
+			server.Options = append(server.Options, depbleed.AllowPackagesOption(allow...))
+		}
+
+		for _, severity := range severities {
+			option, err := parseSeverityOption(severity)
+
+			if err != nil {
+				return err
+			}
+
+			server.Options = append(server.Options, option)
+		}
+
+		cmd.SilenceUsage = true
+
+		return server.Serve(os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	lspCmd.Flags().StringArrayVar(&severities, "severity", nil, "Severity of a leak kind, like third-party=warning (can be repeated)")
+
+	rootCmd.AddCommand(&lspCmd)
+}
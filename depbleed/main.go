@@ -1,11 +1,11 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"go/build"
 	"os"
 	"path/filepath"
+	"strings"
 
 	depbleed "github.com/depbleed/go/go-depbleed"
 	"github.com/spf13/cobra"
@@ -17,22 +17,30 @@ type LintingError struct{}
 func (LintingError) Error() string { return "linting error" }
 
 var (
-	noFail     bool
-	useVCSRoot bool
+	noFail      bool
+	useVCSRoot  bool
+	format      string
+	configPath  string
+	allow       []string
+	goVersion   string
+	severities  []string
+	baseline    string
+	newBaseline string
+	since       string
+	tests       bool
+	tags        []string
+	platforms   []string
 )
 
 var rootCmd = cobra.Command{
-	Use:   "depbleed [path/package]",
+	Use:   "depbleed [packages]",
 	Short: "A Go linter that reports dependency bleeding",
+	Args:  cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) > 1 {
-			return errors.New("too many arguments")
-		}
-
-		path := "."
+		patterns := args
 
-		if len(args) == 1 {
-			path = args[0]
+		if len(patterns) == 0 {
+			patterns = []string{"."}
 		}
 
 		wd, err := os.Getwd()
@@ -45,22 +53,22 @@ var rootCmd = cobra.Command{
 
 		cmd.SilenceUsage = true
 
-		var filenamePath string
+		groups, files, err := groupPatterns(wd, patterns)
 
-		if info, err := os.Stat(path); err == nil && !info.IsDir() {
-			filenamePath, _ = filepath.Abs(path)
+		if err != nil {
+			return err
+		}
 
-			if !filepath.IsAbs(path) {
-				path = "./" + filepath.Dir(path)
-			} else {
-				path = filepath.Dir(path)
-			}
+		// The output format is shared: it comes from the configuration of the
+		// first pattern.
+		if config := groups[0].config; config != nil && config.Format != "" && !cmd.Flags().Changed("format") {
+			format = config.Format
 		}
 
-		packagePaths, err := depbleed.GetPackagePaths(gopath, path)
+		reporter, err := newReporter(format, wd)
 
 		if err != nil {
-			return fmt.Errorf("could not get package paths: %s", err)
+			return err
 		}
 
 		failed := false
@@ -71,34 +79,174 @@ var rootCmd = cobra.Command{
 			options = append(options, depbleed.UseVCSRootOption(gopath))
 		}
 
-		for _, packagePath := range packagePaths {
-			packageInfo, err := depbleed.GetPackageInfo(packagePath, options...)
+		if goVersion != "" {
+			options = append(options, depbleed.GoVersionOption(goVersion))
+		}
+
+		if len(allow) > 0 {
+			options = append(options, depbleed.AllowPackagesOption(allow...))
+		}
+
+		if tests {
+			options = append(options, depbleed.IncludeTestsOption())
+		}
+
+		for _, severity := range severities {
+			option, err := parseSeverityOption(severity)
 
 			if err != nil {
 				return err
 			}
 
-			leaks := packageInfo.Leaks()
+			options = append(options, option)
+		}
 
-			for _, leak := range leaks {
-				if filenamePath != "" && filenamePath != leak.Position.Filename {
-					continue
-				}
+		configurations, err := getBuildConfigurations()
 
-				relPath, err := filepath.Rel(wd, leak.Position.Filename)
+		if err != nil {
+			return err
+		}
+
+		// Without build configurations, packages are analyzed once for the
+		// default one.
+		configurationOptions := [][]depbleed.Option{options}
+
+		if len(configurations) > 0 {
+			configurationOptions = nil
+
+			for _, configuration := range configurations {
+				configurationOptions = append(configurationOptions, append(options[:len(options):len(options)], depbleed.BuildConfigurationOption(configuration)))
+			}
+		}
+
+		var packageInfos []depbleed.PackageInfo
+
+		for _, options := range configurationOptions {
+			for _, group := range groups {
+				infos, err := group.load(options...)
 
 				if err != nil {
-					relPath = leak.Position.Filename
+					return err
 				}
 
-				fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", relPath, leak.Position.Line, leak.Position.Column, leak)
+				packageInfos = append(packageInfos, infos...)
 			}
+		}
+
+		var knownLeaks *depbleed.Baseline
+
+		if baseline != "" {
+			if knownLeaks, err = depbleed.ReadBaseline(baseline); err != nil {
+				return err
+			}
+		}
+
+		var previousLeaks *depbleed.Baseline
 
-			if len(leaks) != 0 {
+		if since != "" {
+			if previousLeaks, err = getRevisionLeaks(since, wd, args); err != nil {
+				return err
+			}
+		}
+
+		var allLeaks depbleed.Leaks
+
+		// The leaks of the test variant of a package are part of that package.
+		var packagePaths []string
+		packageLeaks := map[string]depbleed.Leaks{}
+		filtered := map[string]bool{}
+
+		// Directives are unused when they are unused in every configuration
+		// they are found in.
+		var unusedSuppressions depbleed.Suppressions
+		suppressionCounts := map[string]int{}
+		unusedCounts := map[string]int{}
+
+		for _, packageInfo := range packageInfos {
+			leaks := packageInfo.Leaks()
+			allLeaks = append(allLeaks, leaks...)
+
+			packagePath := packageInfo.Package.Path()
+
+			if _, ok := packageLeaks[packagePath]; !ok {
+				packagePaths = append(packagePaths, packagePath)
+			}
+
+			packageLeaks[packagePath] = append(packageLeaks[packagePath], leaks...)
+			filtered[packagePath] = filtered[packagePath] || files.filters(packageInfo.Dir)
+
+			for _, suppression := range packageInfo.GetSuppressions() {
+				suppressionCounts[suppression.Position.String()]++
+			}
+
+			for _, suppression := range packageInfo.UnusedSuppressions() {
+				if unusedCounts[suppression.Position.String()] == 0 {
+					unusedSuppressions = append(unusedSuppressions, suppression)
+				}
+
+				unusedCounts[suppression.Position.String()]++
+			}
+		}
+
+		// The same leaks are found in several build configurations.
+		allLeaks = depbleed.MergeLeaks(allLeaks)
+
+		for _, leak := range allLeaks {
+			if !files.contains(leak.Position.Filename) {
+				continue
+			}
+
+			// Known leaks are neither reported nor make the linting fail.
+			if knownLeaks != nil && knownLeaks.Contains(leak) {
+				continue
+			}
+
+			// So are the leaks that already existed at the revision.
+			if previousLeaks != nil && previousLeaks.Contains(leak) {
+				continue
+			}
+
+			if err := reporter.report(leak, relativePath(wd, leak.Position.Filename)); err != nil {
+				return fmt.Errorf("could not report leak: %s", err)
+			}
+
+			// Only errors make the linting fail.
+			if leak.Severity == depbleed.SeverityError {
 				failed = true
 			}
 		}
 
+		// Unused suppressions are warnings: they never make the linting fail.
+		for _, suppression := range unusedSuppressions {
+			if !files.contains(suppression.Position.Filename) || unusedCounts[suppression.Position.String()] < suppressionCounts[suppression.Position.String()] {
+				continue
+			}
+
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: unused //depbleed:ignore directive\n", relativePath(wd, suppression.Position.Filename), suppression.Position.Line, suppression.Position.Column)
+		}
+
+		// Fixed leaks can only be told when the whole package is analyzed.
+		if knownLeaks != nil {
+			for _, packagePath := range packagePaths {
+				if filtered[packagePath] {
+					continue
+				}
+
+				for _, entry := range knownLeaks.Fixed(packagePath, packageLeaks[packagePath]) {
+					fmt.Fprintf(os.Stderr, "%s: %s: fixed leak of %s, it can be removed from the baseline\n", entry.Package, entry.Object, entry.Type)
+				}
+			}
+		}
+
+		if err := reporter.close(); err != nil {
+			return fmt.Errorf("could not report leaks: %s", err)
+		}
+
+		// Writing a baseline accepts the current leaks.
+		if newBaseline != "" {
+			return writeBaseline(newBaseline, depbleed.NewBaseline(allLeaks))
+		}
+
 		if !noFail && failed {
 			return LintingError{}
 		}
@@ -108,9 +256,121 @@ var rootCmd = cobra.Command{
 	SilenceErrors: true,
 }
 
+// getConfig returns the configuration to use to analyze the specified path.
+//
+// Unless a configuration file was specified, it is looked for from the
+// analyzed directory upwards.
+func getConfig(path string) (*depbleed.Config, error) {
+	if configPath != "" {
+		return depbleed.ReadConfig(configPath)
+	}
+
+	dir := "."
+
+	if filepath.IsAbs(path) || strings.HasPrefix(path, ".") {
+		dir = strings.TrimSuffix(path, "...")
+	}
+
+	config, err := depbleed.FindConfig(dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not find configuration file: %s", err)
+	}
+
+	return config, nil
+}
+
+// getBuildConfigurations returns the build configurations to analyze, or nil
+// to only analyze the default one.
+func getBuildConfigurations() ([]depbleed.BuildConfiguration, error) {
+	if len(platforms) == 0 {
+		if len(tags) == 0 {
+			return nil, nil
+		}
+
+		return []depbleed.BuildConfiguration{{GOOS: build.Default.GOOS, GOARCH: build.Default.GOARCH, Tags: tags}}, nil
+	}
+
+	var result []depbleed.BuildConfiguration
+
+	for _, platform := range platforms {
+		configuration, err := depbleed.ParsePlatform(platform)
+
+		if err != nil {
+			return nil, err
+		}
+
+		configuration.Tags = tags
+		result = append(result, configuration)
+	}
+
+	return result, nil
+}
+
+// writeBaseline writes a baseline file.
+func writeBaseline(filename string, baseline depbleed.Baseline) error {
+	f, err := os.Create(filename)
+
+	if err != nil {
+		return fmt.Errorf("could not write baseline: %s", err)
+	}
+
+	if err := baseline.Write(f); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// parseSeverityOption parses a `kind=level` severity flag.
+func parseSeverityOption(value string) (depbleed.Option, error) {
+	parts := strings.SplitN(value, "=", 2)
+
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid severity \"%s\": expected kind=level", value)
+	}
+
+	kind, err := depbleed.ParseLeakKind(parts[0])
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity \"%s\": %s", value, err)
+	}
+
+	severity, err := depbleed.ParseSeverity(parts[1])
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity \"%s\": %s", value, err)
+	}
+
+	return depbleed.SeverityOption(kind, severity), nil
+}
+
+// relativePath returns the path of a file relative to the working directory,
+// if possible.
+func relativePath(wd string, filename string) string {
+	if relPath, err := filepath.Rel(wd, filename); err == nil {
+		return relPath
+	}
+
+	return filename
+}
+
 func init() {
-	rootCmd.Flags().BoolVar(&noFail, "no-fail", false, "Don't fail on errors")
+	rootCmd.PersistentFlags().BoolVar(&noFail, "no-fail", false, "Don't fail on errors")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Configuration file (defaults to the closest "+depbleed.ConfigFilename+")")
+	rootCmd.PersistentFlags().StringArrayVar(&allow, "allow", nil, "Package pattern whose types are treated as standard types (can be repeated)")
+	rootCmd.PersistentFlags().StringVar(&goVersion, "go-version", "", "Go version whose standard packages are allowed, like 1.21.0 (defaults to the toolchain in use)")
 	rootCmd.Flags().BoolVarP(&useVCSRoot, "use-vcs-root", "g", false, "Use VCS root as package root")
+	rootCmd.Flags().StringArrayVar(&severities, "severity", nil, "Severity of a leak kind, like third-party=warning (can be repeated)")
+	rootCmd.Flags().StringVar(&baseline, "baseline", "", "Baseline file of known leaks, that are neither reported nor make the linting fail")
+	rootCmd.Flags().StringVar(&newBaseline, "write-baseline", "", "Write the current leaks to a baseline file")
+	rootCmd.Flags().BoolVar(&tests, "tests", false, "Also analyze the test files of the packages and their external test packages")
+	rootCmd.Flags().StringSliceVar(&tags, "tags", nil, "Comma-separated build tags to analyze the packages with")
+	rootCmd.Flags().StringSliceVar(&platforms, "platforms", nil, "Comma-separated platforms to analyze the packages for, like linux/amd64,windows/amd64,darwin/arm64")
+	rootCmd.Flags().StringVar(&since, "since", "", "Only report leaks that are new since a git revision, like origin/main")
+	rootCmd.Flags().StringVar(&format, "format", "text", "Output format: text (on stderr), json (one object per line, on stdout) or sarif (on stdout)")
 }
 
 func main() {
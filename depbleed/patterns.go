@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+// patternGroup is a set of package patterns that are resolved together.
+type patternGroup struct {
+	// dir is the directory of the module the patterns belong to, or empty to
+	// resolve the patterns from the working directory.
+	dir string
+	// config is the configuration that applies to the patterns, if any.
+	config   *depbleed.Config
+	patterns []string
+}
+
+// load loads the packages that match the patterns of the group.
+//
+// The configuration of the group is applied before the specified options, so
+// that they take precedence.
+func (g patternGroup) load(options ...depbleed.Option) ([]depbleed.PackageInfo, error) {
+	packagePaths, err := depbleed.ListPackagePaths(g.dir, g.patterns...)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not get package paths: %s", err)
+	}
+
+	if g.config != nil {
+		options = append([]depbleed.Option{depbleed.ConfigOption(*g.config)}, options...)
+	}
+
+	if g.dir != "" {
+		options = append(options[:len(options):len(options)], depbleed.DirOption(g.dir))
+	}
+
+	return depbleed.GetPackageInfos(packagePaths, options...)
+}
+
+// fileFilter maps the directories of the files given as arguments to these
+// files: only they are reported for their packages.
+type fileFilter map[string]map[string]bool
+
+// filters checks whether the reports for a directory are restricted to some
+// of its files.
+func (f fileFilter) filters(dir string) bool {
+	_, ok := f[dir]
+
+	return ok
+}
+
+// contains checks whether the reports for a file are kept.
+func (f fileFilter) contains(filename string) bool {
+	files, ok := f[filepath.Dir(filename)]
+
+	return !ok || files[filename]
+}
+
+// groupPatterns groups package patterns by the module they belong to and the
+// configuration that applies to them, so that each group is resolved from
+// within its module.
+//
+// Import paths belong to the module of the working directory, like with the go
+// tool, while directories belong to the module that contains them. Files are
+// replaced by their directory, and only reported through the returned filter.
+func groupPatterns(wd string, patterns []string) ([]patternGroup, fileFilter, error) {
+	defaultDir := ""
+	module, err := depbleed.FindModule(wd)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find module: %s", err)
+	}
+
+	if module != nil {
+		defaultDir = module.Dir
+	}
+
+	var groups []patternGroup
+	indexes := map[[2]string]int{}
+	files := fileFilter{}
+
+	for _, pattern := range patterns {
+		dir := defaultDir
+		config, err := getConfig(pattern)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if filepath.IsAbs(pattern) || strings.HasPrefix(pattern, ".") {
+			pattern, err = filepath.Abs(pattern)
+
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot determine absolute path for \"%s\": %s", pattern, err)
+			}
+
+			if info, err := os.Stat(pattern); err == nil && !info.IsDir() {
+				if files[filepath.Dir(pattern)] == nil {
+					files[filepath.Dir(pattern)] = map[string]bool{}
+				}
+
+				files[filepath.Dir(pattern)][pattern] = true
+				pattern = filepath.Dir(pattern)
+			}
+
+			module, err := depbleed.FindModule(strings.TrimSuffix(pattern, "..."))
+
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not find module: %s", err)
+			}
+
+			dir = ""
+
+			if module != nil {
+				dir = module.Dir
+			}
+		}
+
+		key := [2]string{dir, ""}
+
+		if config != nil {
+			key[1] = config.Dir
+		}
+
+		index, ok := indexes[key]
+
+		if !ok {
+			index = len(groups)
+			indexes[key] = index
+			groups = append(groups, patternGroup{dir: dir, config: config})
+		}
+
+		groups[index].patterns = append(groups[index].patterns, pattern)
+	}
+
+	return groups, files, nil
+}
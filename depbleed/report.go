@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+// reporter reports leaks in a specific format.
+type reporter interface {
+	// report reports a leak. The filename of the leak position is relative to
+	// the working directory.
+	report(leak depbleed.Leak, filename string) error
+	// close is called once all leaks were reported.
+	close() error
+}
+
+// newReporter returns a reporter for the specified format. The working
+// directory is used to find the repository root, when needed.
+func newReporter(format string, wd string) (reporter, error) {
+	switch format {
+	case "text":
+		return textReporter{w: os.Stderr}, nil
+	case "json":
+		return jsonReporter{encoder: json.NewEncoder(os.Stdout)}, nil
+	case "sarif":
+		return newSARIFReporter(os.Stdout, getRepositoryRoot(wd)), nil
+	default:
+		return nil, fmt.Errorf("unknown format \"%s\"", format)
+	}
+}
+
+// getConfigurations returns the names of the build configurations a leak
+// appears in.
+func getConfigurations(leak depbleed.Leak) (result []string) {
+	for _, configuration := range leak.Configurations {
+		result = append(result, configuration.String())
+	}
+
+	return
+}
+
+// textReporter reports leaks as `file:line:column: message` lines. Leaks that
+// are not errors have their severity level before the message, and the build
+// configurations they appear in, if any, after it.
+type textReporter struct {
+	w io.Writer
+}
+
+func (r textReporter) report(leak depbleed.Leak, filename string) error {
+	message := leak.Error()
+
+	if configurations := getConfigurations(leak); len(configurations) > 0 {
+		message = fmt.Sprintf("%s (%s)", message, strings.Join(configurations, ", "))
+	}
+
+	// Errors are reported as plain messages, like other linters do.
+	if leak.Severity != depbleed.SeverityError {
+		_, err := fmt.Fprintf(r.w, "%s:%d:%d: %s: %s\n", filename, leak.Position.Line, leak.Position.Column, leak.Severity, message)
+
+		return err
+	}
+
+	_, err := fmt.Fprintf(r.w, "%s:%d:%d: %s\n", filename, leak.Position.Line, leak.Position.Column, message)
+
+	return err
+}
+
+func (textReporter) close() error {
+	return nil
+}
+
+// jsonReporter reports leaks as JSON objects, one per line.
+type jsonReporter struct {
+	encoder *json.Encoder
+}
+
+type jsonPosition struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+type jsonLeak struct {
+	Position       jsonPosition `json:"position"`
+	Object         string       `json:"object"`
+	ObjectKind     string       `json:"object_kind"`
+	Type           string       `json:"type"`
+	Package        string       `json:"package"`
+	Origin         string       `json:"origin"`
+	Kind           string       `json:"kind"`
+	Level          string       `json:"level"`
+	Reasons        []string     `json:"reasons"`
+	Path           []jsonStep   `json:"path"`
+	Message        string       `json:"message"`
+	Configurations []string     `json:"configurations,omitempty"`
+}
+
+type jsonStep struct {
+	Kind  string `json:"kind"`
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+}
+
+func (r jsonReporter) report(leak depbleed.Leak, filename string) error {
+	origin := "global"
+
+	switch {
+	case leak.IsInternal():
+		origin = "internal"
+	case leak.IsVendored():
+		origin = "vendored"
+	}
+
+	path := []jsonStep{}
+
+	for _, step := range leak.Path.Steps {
+		path = append(path, jsonStep{
+			Kind:  step.Kind.String(),
+			Index: step.Index,
+			Name:  step.Name,
+		})
+	}
+
+	return r.encoder.Encode(jsonLeak{
+		Position: jsonPosition{
+			Filename: filename,
+			Line:     leak.Position.Line,
+			Column:   leak.Position.Column,
+		},
+		Object:         leak.Object.Name(),
+		ObjectKind:     leak.ObjectKind(),
+		Type:           depbleed.GetTypeShortName(leak.Type()),
+		Package:        leak.PackagePath(),
+		Origin:         origin,
+		Kind:           leak.Kind().String(),
+		Level:          leak.Severity.String(),
+		Reasons:        leak.Reasons(),
+		Path:           path,
+		Message:        leak.Error(),
+		Configurations: getConfigurations(leak),
+	})
+}
+
+func (jsonReporter) close() error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+// getLeaks returns the leaks of a package.
+func getLeaks(t *testing.T, path string, options ...depbleed.Option) depbleed.Leaks {
+	packageInfo, err := depbleed.GetPackageInfo(path, options...)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	return packageInfo.Leaks()
+}
+
+func TestJSONReporter(t *testing.T) {
+	leaks := getLeaks(t, "github.com/depbleed/go/examples/exstruct", depbleed.SeverityOption(depbleed.VendoredLeak, depbleed.SeverityWarning))
+
+	if len(leaks) != 1 {
+		t.Fatalf("expected 1 leak but got %d", len(leaks))
+	}
+
+	var buffer bytes.Buffer
+	r := jsonReporter{encoder: json.NewEncoder(&buffer)}
+
+	if err := r.report(leaks[0], "lib.go"); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if err := r.close(); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	var leak jsonLeak
+
+	if err := json.Unmarshal(buffer.Bytes(), &leak); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if expected := (jsonPosition{Filename: "lib.go", Line: 9, Column: 2}); leak.Position != expected {
+		t.Errorf("expected %v but got %v", expected, leak.Position)
+	}
+
+	if expected := "vendored"; leak.Kind != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leak.Kind)
+	}
+
+	if expected := "warning"; leak.Level != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leak.Level)
+	}
+
+	if expected := "github.com/depbleed/go/examples/exstruct/vendor/a"; leak.Package != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leak.Package)
+	}
+
+	if expected := "A"; leak.Object != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leak.Object)
+	}
+
+	if expected := "field"; leak.ObjectKind != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leak.ObjectKind)
+	}
+
+	if expected := "a.Type"; leak.Type != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leak.Type)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifRoot is the base identifier for the artifacts locations, which are
+// relative to the repository root.
+const sarifRoot = "SRCROOT"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	HelpURI              string             `json:"helpUri"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifRules are the rules reported by depbleed, one per leak kind.
+var sarifRules = []sarifRule{
+	depbleed.VendoredLeak: {
+		ID:               "vendored-type",
+		Name:             "VendoredType",
+		ShortDescription: sarifMessage{Text: "Exported API exposes a vendorized type"},
+		FullDescription: sarifMessage{
+			Text: "An exported identifier exposes a type from a vendored dependency. " +
+				"Other packages that vendor the same dependency get an incompatible type.",
+		},
+		HelpURI:              "https://github.com/depbleed/go#rationale",
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	depbleed.ThirdPartyLeak: {
+		ID:               "global-type",
+		Name:             "GlobalType",
+		ShortDescription: sarifMessage{Text: "Exported API exposes a global type"},
+		FullDescription: sarifMessage{
+			Text: "An exported identifier exposes a type from a non-standard package " +
+				"outside of the package root, which makes that dependency part of the API.",
+		},
+		HelpURI:              "https://github.com/depbleed/go#rationale",
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	depbleed.InternalLeak: {
+		ID:               "internal-type",
+		Name:             "InternalType",
+		ShortDescription: sarifMessage{Text: "Exported API exposes an internal type"},
+		FullDescription: sarifMessage{
+			Text: "An exported identifier exposes a type from an internal package " +
+				"that some importers of the package cannot import, and thus cannot name.",
+		},
+		HelpURI:              "https://github.com/depbleed/go#rationale",
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	depbleed.UnexportedReachableLeak: {
+		ID:               "unexported-reachable-type",
+		Name:             "UnexportedReachableType",
+		ShortDescription: sarifMessage{Text: "Exported API exposes a type through an unexported type"},
+		FullDescription: sarifMessage{
+			Text: "An exported identifier exposes an external type through an unexported type " +
+				"that is reachable from the exported API, like the type of an exported field.",
+		},
+		HelpURI:              "https://github.com/depbleed/go#rationale",
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	depbleed.PromotedLeak: {
+		ID:               "promoted-method",
+		Name:             "PromotedMethod",
+		ShortDescription: sarifMessage{Text: "Exported API exposes a type through a promoted method"},
+		FullDescription: sarifMessage{
+			Text: "An exported type exposes an external type through a method promoted " +
+				"from one of its embedded fields or types.",
+		},
+		HelpURI:              "https://github.com/depbleed/go#rationale",
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+}
+
+// sarifLevel returns the SARIF level of a leak.
+func sarifLevel(leak depbleed.Leak) string {
+	switch leak.Severity {
+	case depbleed.SeverityWarning:
+		return "warning"
+	case depbleed.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// getRepositoryRoot returns the root of the repository that contains `dir`,
+// or `dir` itself if it is not in a repository.
+func getRepositoryRoot(dir string) string {
+	output, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+
+	if err != nil {
+		return dir
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+// sarifReporter reports leaks as a SARIF log, once all leaks are known.
+type sarifReporter struct {
+	w       io.Writer
+	root    string
+	results []sarifResult
+}
+
+func newSARIFReporter(w io.Writer, root string) *sarifReporter {
+	// This is necessary because `git rev-parse` will return resolved symlinks.
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolvedRoot
+	}
+
+	return &sarifReporter{
+		w:       w,
+		root:    root,
+		results: []sarifResult{},
+	}
+}
+
+func (r *sarifReporter) report(leak depbleed.Leak, filename string) error {
+	location := sarifArtifactLocation{URI: filepath.ToSlash(leak.Position.Filename)}
+
+	if path, err := filepath.EvalSymlinks(leak.Position.Filename); err == nil {
+		if path, err := filepath.Rel(r.root, path); err == nil && !strings.HasPrefix(path, "..") {
+			location = sarifArtifactLocation{
+				URI:       (&url.URL{Path: filepath.ToSlash(path)}).String(),
+				URIBaseID: sarifRoot,
+			}
+		}
+	}
+
+	// Rules are indexed by leak kind.
+	index := int(leak.Kind())
+
+	properties := map[string]string{"kind": leak.Kind().String()}
+
+	if configurations := getConfigurations(leak); len(configurations) > 0 {
+		properties["configurations"] = strings.Join(configurations, ", ")
+	}
+
+	r.results = append(r.results, sarifResult{
+		RuleID:    sarifRules[index].ID,
+		RuleIndex: index,
+		Level:     sarifLevel(leak),
+		Message:   sarifMessage{Text: leak.Error()},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: location,
+					Region: sarifRegion{
+						StartLine:   leak.Position.Line,
+						StartColumn: leak.Position.Column,
+					},
+				},
+			},
+		},
+		Properties: properties,
+	})
+
+	return nil
+}
+
+func (r *sarifReporter) close() error {
+	rootURI := &url.URL{Scheme: "file", Path: filepath.ToSlash(r.root) + "/"}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "depbleed",
+						InformationURI: "https://github.com/depbleed/go",
+						Rules:          sarifRules,
+					},
+				},
+				OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+					sarifRoot: {URI: rootURI.String()},
+				},
+				Results: r.results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("cannot write SARIF log: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+func TestSARIFReporter(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "examples"))
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	options := []depbleed.Option{
+		depbleed.SeverityOption(depbleed.ThirdPartyLeak, depbleed.SeverityWarning),
+		depbleed.SeverityOption(depbleed.InternalLeak, depbleed.SeverityInfo),
+	}
+
+	var leaks depbleed.Leaks
+
+	for _, example := range []string{"excomplete", "exinternal", "exunexported", "expromoted"} {
+		leaks = append(leaks, getLeaks(t, "github.com/depbleed/go/examples/"+example, options...)...)
+	}
+
+	var buffer bytes.Buffer
+	r := newSARIFReporter(&buffer, root)
+
+	for _, leak := range leaks {
+		if err := r.report(leak, leak.Position.Filename); err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+	}
+
+	if err := r.close(); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	var log sarifLog
+
+	if err := json.Unmarshal(buffer.Bytes(), &log); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if len(log.Runs) != 1 {
+		t.Fatalf("expected 1 run but got %d", len(log.Runs))
+	}
+
+	run := log.Runs[0]
+
+	var ids []string
+
+	for _, rule := range run.Tool.Driver.Rules {
+		ids = append(ids, rule.ID)
+	}
+
+	expectedIDs := []string{"vendored-type", "global-type", "internal-type", "unexported-reachable-type", "promoted-method"}
+
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("expected %v but got %v", expectedIDs, ids)
+	}
+
+	if len(run.Results) != len(leaks) {
+		t.Fatalf("expected %d results but got %d", len(leaks), len(run.Results))
+	}
+
+	expectedLevels := map[depbleed.LeakKind]string{
+		depbleed.VendoredLeak:            "error",
+		depbleed.ThirdPartyLeak:          "warning",
+		depbleed.InternalLeak:            "note",
+		depbleed.UnexportedReachableLeak: "error",
+		depbleed.PromotedLeak:            "error",
+	}
+
+	kinds := map[depbleed.LeakKind]bool{}
+
+	for index, result := range run.Results {
+		kind := leaks[index].Kind()
+		kinds[kind] = true
+
+		if result.RuleIndex != int(kind) {
+			t.Errorf("expected rule index %d for %s but got %d", int(kind), leaks[index], result.RuleIndex)
+		}
+
+		if expected := expectedIDs[kind]; result.RuleID != expected {
+			t.Errorf("expected rule \"%s\" for %s but got \"%s\"", expected, leaks[index], result.RuleID)
+		}
+
+		if expected := expectedLevels[kind]; result.Level != expected {
+			t.Errorf("expected level \"%s\" for %s but got \"%s\"", expected, leaks[index], result.Level)
+		}
+
+		location := result.Locations[0].PhysicalLocation.ArtifactLocation
+
+		if location.URIBaseID != sarifRoot {
+			t.Errorf("expected base \"%s\" for %s but got \"%s\"", sarifRoot, leaks[index], location.URIBaseID)
+		}
+
+		uri, err := url.Parse(location.URI)
+
+		if err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+
+		if uri.IsAbs() || filepath.IsAbs(uri.Path) {
+			t.Errorf("expected a relative URI for %s but got \"%s\"", leaks[index], location.URI)
+		}
+
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(uri.Path))); err != nil {
+			t.Errorf("expected \"%s\" to be relative to the root but got: %s", location.URI, err)
+		}
+	}
+
+	for _, kind := range depbleed.LeakKinds {
+		if !kinds[kind] {
+			t.Errorf("expected a result of kind %s", kind)
+		}
+	}
+
+	if expected := (&url.URL{Scheme: "file", Path: filepath.ToSlash(r.root) + "/"}).String(); run.OriginalURIBaseIDs[sarifRoot].URI != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, run.OriginalURIBaseIDs[sarifRoot].URI)
+	}
+}
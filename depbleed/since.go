@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+// git runs a git command and returns its trimmed output.
+func git(args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// getGOPATHImportPath returns the import path of a directory within the
+// specified GOPATH, if it is in it.
+func getGOPATHImportPath(gopath string, dir string) (string, bool) {
+	for _, p := range filepath.SplitList(gopath) {
+		rel, err := filepath.Rel(filepath.Join(p, "src"), dir)
+
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel), true
+		}
+	}
+
+	return "", false
+}
+
+// getRevisionLeaks returns the leaks of the specified arguments at a git
+// revision, as a baseline.
+//
+// The revision is checked out in a temporary worktree, where depbleed runs
+// with the same arguments. GOPATH projects are checked out at the same import
+// path in a temporary GOPATH, that takes precedence over the current one.
+func getRevisionLeaks(revision string, wd string, args []string) (*depbleed.Baseline, error) {
+	root, err := git("-C", wd, "rev-parse", "--show-toplevel")
+
+	if err != nil {
+		return nil, fmt.Errorf("could not find repository: %s", err)
+	}
+
+	// This is necessary because `git rev-parse` will return resolved symlinks.
+	if resolvedWd, err := filepath.EvalSymlinks(wd); err == nil {
+		wd = resolvedWd
+	}
+
+	rel, err := filepath.Rel(root, wd)
+
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("working directory \"%s\" is not in repository \"%s\"", wd, root)
+	}
+
+	tmp, err := ioutil.TempDir("", "depbleed")
+
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary directory: %s", err)
+	}
+
+	defer os.RemoveAll(tmp)
+
+	worktree := filepath.Join(tmp, filepath.Base(root))
+	env := os.Environ()
+	module, err := depbleed.FindModule(wd)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not find module: %s", err)
+	}
+
+	if module == nil {
+		if importPath, ok := getGOPATHImportPath(build.Default.GOPATH, root); ok {
+			worktree = filepath.Join(tmp, "src", filepath.FromSlash(importPath))
+			env = append(env, "GOPATH="+tmp+string(filepath.ListSeparator)+build.Default.GOPATH)
+		}
+	}
+
+	if _, err := git("-C", root, "worktree", "add", "--detach", worktree, revision); err != nil {
+		return nil, fmt.Errorf("could not check out revision %s: %s", revision, err)
+	}
+
+	defer git("-C", root, "worktree", "remove", "--force", worktree)
+
+	// Absolute paths within the repository are moved to the worktree.
+	mapPath := func(p string) string {
+		if !filepath.IsAbs(p) {
+			return p
+		}
+
+		if rel, err := filepath.Rel(root, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.Join(worktree, rel)
+		}
+
+		return p
+	}
+
+	filename := filepath.Join(tmp, "baseline.json")
+	revisionArgs := []string{"--no-fail", "--format", "json", "--write-baseline", filename}
+
+	if useVCSRoot {
+		revisionArgs = append(revisionArgs, "--use-vcs-root")
+	}
+
+	if tests {
+		revisionArgs = append(revisionArgs, "--tests")
+	}
+
+	if len(tags) > 0 {
+		revisionArgs = append(revisionArgs, "--tags", strings.Join(tags, ","))
+	}
+
+	if len(platforms) > 0 {
+		revisionArgs = append(revisionArgs, "--platforms", strings.Join(platforms, ","))
+	}
+
+	if configPath != "" {
+		if absConfigPath, err := filepath.Abs(configPath); err == nil {
+			revisionArgs = append(revisionArgs, "--config", mapPath(absConfigPath))
+		}
+	}
+
+	for _, pattern := range allow {
+		revisionArgs = append(revisionArgs, "--allow", pattern)
+	}
+
+	if goVersion != "" {
+		revisionArgs = append(revisionArgs, "--go-version", goVersion)
+	}
+
+	dir := filepath.Join(worktree, rel)
+
+	// Neither does a working directory that doesn't exist at the revision.
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return &depbleed.Baseline{}, nil
+	}
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	var revisionPaths []string
+
+	for _, arg := range args {
+		arg = mapPath(arg)
+
+		// Directories that don't exist at the revision have no leaks there...
+		if filepath.IsAbs(arg) || strings.HasPrefix(arg, ".") {
+			p := strings.TrimSuffix(arg, "...")
+
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(dir, p)
+			}
+
+			if _, err := os.Stat(p); os.IsNotExist(err) {
+				continue
+			}
+		}
+
+		revisionPaths = append(revisionPaths, arg)
+	}
+
+	if len(revisionPaths) == 0 {
+		return &depbleed.Baseline{}, nil
+	}
+
+	revisionArgs = append(revisionArgs, revisionPaths...)
+
+	executable, err := os.Executable()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not find depbleed executable: %s", err)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(executable, revisionArgs...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not analyze revision %s: %s: %s", revision, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return depbleed.ReadBaseline(filename)
+}
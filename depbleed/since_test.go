@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// mainEnv is set when the test binary must run as the depbleed command, which
+// is how --since analyzes revisions.
+const mainEnv = "DEPBLEED_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(mainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// writeFiles writes files relative to a directory.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+	}
+}
+
+// runGit runs a git command in a directory.
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=depbleed", "-c", "user.email=depbleed@example.com"}, args...)...)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %s: %s", args, err, output)
+	}
+}
+
+// runSince runs depbleed with --since in a directory, and returns the objects
+// of the reported leaks.
+func runSince(t *testing.T, dir string, env []string, revision string) []string {
+	cmd := exec.Command(os.Args[0], "--no-fail", "--format", "json", "--since", revision, "./...")
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), mainEnv+"=1"), env...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s: %s", err, stderr.String())
+	}
+
+	var objects []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		var leak jsonLeak
+
+		if err := json.Unmarshal(scanner.Bytes(), &leak); err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+
+		objects = append(objects, leak.Object)
+	}
+
+	return objects
+}
+
+func TestSince(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// Path is the path of the repository within the temporary directory,
+		// which is used as the GOPATH if the repository has no go.mod.
+		Path string
+		// Import is the import path of the leaked package.
+		Import string
+		Files  map[string]string
+		Env    []string
+	}{
+		{
+			Name:   "module",
+			Path:   "since",
+			Import: "example.com/a",
+			Files: map[string]string{
+				"go.mod":   "module example.com/since\n\ngo 1.21\n\nrequire example.com/a v0.0.0\n\nreplace example.com/a => ./a\n",
+				"a/go.mod": "module example.com/a\n\ngo 1.21\n",
+				"a/a.go":   "package a\n\ntype Int int\ntype Bool bool\n",
+			},
+			Env: []string{"GO111MODULE=on", "GOFLAGS=-mod=mod"},
+		},
+		{
+			Name:   "gopath",
+			Path:   "src/example.com/since",
+			Import: "a",
+			Files: map[string]string{
+				"vendor/a/a.go": "package a\n\ntype Int int\ntype Bool bool\n",
+			},
+			Env: []string{"GO111MODULE=off"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tmp, err := ioutil.TempDir("", "depbleed")
+
+			if err != nil {
+				t.Fatalf("expected no error but got: %s", err)
+			}
+
+			defer os.RemoveAll(tmp)
+
+			dir := filepath.Join(tmp, filepath.FromSlash(testCase.Path))
+			env := append(testCase.Env, "GOPATH="+tmp)
+
+			content := "package since\n\nimport \"" + testCase.Import + "\"\n\nvar A a.Int\n"
+
+			writeFiles(t, dir, testCase.Files)
+			writeFiles(t, dir, map[string]string{"lib.go": content})
+
+			runGit(t, dir, "init", "-q")
+			runGit(t, dir, "add", "-A")
+			runGit(t, dir, "commit", "-q", "-m", "Leak A")
+
+			if objects := runSince(t, dir, env, "HEAD"); len(objects) != 0 {
+				t.Errorf("expected no new leaks but got: %v", objects)
+			}
+
+			writeFiles(t, dir, map[string]string{"lib.go": content + "\nvar B a.Bool\n"})
+
+			if objects, expected := runSince(t, dir, env, "HEAD"), []string{"B"}; !reflect.DeepEqual(objects, expected) {
+				t.Errorf("expected %v but got %v", expected, objects)
+			}
+
+			runGit(t, dir, "commit", "-q", "-a", "-m", "Leak B")
+
+			// Leaks whose offending type changed are new.
+			writeFiles(t, dir, map[string]string{"lib.go": content + "\nvar B a.Int\n"})
+
+			if objects, expected := runSince(t, dir, env, "HEAD"), []string{"B"}; !reflect.DeepEqual(objects, expected) {
+				t.Errorf("expected %v but got %v", expected, objects)
+			}
+
+			if objects, expected := runSince(t, dir, env, "HEAD~1"), []string{"B"}; !reflect.DeepEqual(objects, expected) {
+				t.Errorf("expected %v but got %v", expected, objects)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package excluded
+
+import "a"
+
+// A is an exported vendor type, in an excluded package.
+var A a.Int
+
+// B is a deliberate leak, in an excluded package.
+var B a.Int //depbleed:ignore
@@ -0,0 +1,17 @@
+package exconfig
+
+import (
+	"a"
+
+	"github.com/depbleed/go/examples/exchan"
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+// A is an exported vendor type.
+var A a.Int
+
+// B exposes a type from another example, which is in a configured root.
+var B exchan.MyType
+
+// C exposes a type from an allowed package.
+var C depbleed.Leak
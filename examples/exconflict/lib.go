@@ -0,0 +1,13 @@
+package exconflict
+
+import (
+	"lib-a"
+	"lib-b"
+)
+
+// Run cannot pass the result of lib-a to lib-b, as they use different copies
+// of lib-foo: `libb.Use(liba.New())` does not compile.
+func Run() {
+	liba.New()
+	_ = libb.Use
+}
@@ -0,0 +1,26 @@
+package exgeneric
+
+import "a"
+
+// Set is a generic type that is not constrained by a dependency type.
+type Set[T comparable] map[T]struct{}
+
+// A instantiates a generic type with a type argument provided by a
+// dependency.
+var A Set[a.Int]
+
+// B is a generic type constrained by types provided by a dependency.
+type B[T a.Int | a.Bool] []T
+
+// C is a generic function constrained by a type provided by a dependency.
+func C[T a.Int | int](T) {}
+
+// D is a generic type provided by a dependency.
+var D a.List[int]
+
+// E instantiates a generic type with a built-in type. Nothing to see here.
+var E Set[int]
+
+// F instantiates a generic type with a pointer to a generic type provided by a
+// dependency.
+var F Set[*a.List[int]]
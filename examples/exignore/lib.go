@@ -0,0 +1,36 @@
+package exignore
+
+import "a"
+
+// A is a deliberate leak.
+//
+//depbleed:ignore a.Int is part of the API on purpose.
+var A a.Int
+
+// B is a deliberate leak too.
+var B a.Int //depbleed:ignore
+
+// C is not a deliberate leak.
+var C a.Int
+
+// D is a struct with a deliberately leaking field.
+type D struct {
+	//depbleed:ignore
+	E a.Int
+	F a.Int
+}
+
+// H is a struct whose fields all leak deliberately.
+//
+//depbleed:ignore
+type H struct {
+	I a.Int
+	J struct {
+		K a.Int
+	}
+}
+
+// G used to leak but doesn't anymore.
+//
+//depbleed:ignore a.Int was part of the API on purpose.
+var G int
@@ -0,0 +1,4 @@
+package impl
+
+// Type is a type from an internal package.
+type Type struct{}
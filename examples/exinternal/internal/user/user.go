@@ -0,0 +1,8 @@
+package user
+
+import "github.com/depbleed/go/examples/exinternal/internal/impl"
+
+// A exposes a type from an internal package. This is not an internal leak: all
+// the importers of this package can import it too. When using the VCS root as
+// package root, this is fine.
+var A impl.Type
@@ -0,0 +1,12 @@
+package exinternal
+
+import "github.com/depbleed/go/examples/exinternal/internal/impl"
+
+// A exposes a type from an internal package, that importers of this package
+// cannot import.
+var A impl.Type
+
+// B exposes a pointer to a type from an internal package.
+func B() *impl.Type {
+	return nil
+}
@@ -0,0 +1,31 @@
+package exliteral
+
+import "a"
+
+// A is a struct literal with a field of a type provided by a dependency.
+var A struct {
+	X a.Int
+}
+
+// B returns an interface literal with a method that exposes a type provided by
+// a dependency.
+func B() interface {
+	M() a.Time
+} {
+	return nil
+}
+
+// C is a struct literal that embeds a type provided by a dependency.
+var C struct {
+	a.Struct
+}
+
+// D is a struct literal with an unexported field. Nothing to see here.
+var D struct {
+	x a.Int
+}
+
+// E is an interface literal that embeds an interface provided by a dependency.
+var E interface {
+	a.Interface
+}
@@ -0,0 +1,6 @@
+package explatform
+
+import "a"
+
+// Common leaks a vendorized type on all platforms.
+func Common() a.Int { return 0 }
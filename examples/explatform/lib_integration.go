@@ -0,0 +1,8 @@
+//go:build integration
+
+package explatform
+
+import "a"
+
+// Endpoint leaks a vendorized type when built with the integration tag.
+func Endpoint() a.String { return "" }
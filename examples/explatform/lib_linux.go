@@ -0,0 +1,6 @@
+package explatform
+
+import "a"
+
+// Handle leaks a vendorized type on Linux.
+func Handle() a.Int { return 0 }
@@ -0,0 +1,6 @@
+package explatform
+
+import "a"
+
+// Handle leaks another vendorized type on Windows.
+func Handle() a.Bool { return false }
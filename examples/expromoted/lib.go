@@ -0,0 +1,32 @@
+package expromoted
+
+import "a"
+
+// Wrapper embeds a vendorized type, whose methods are promoted.
+type Wrapper struct {
+	a.Client
+}
+
+// Deep promotes the methods of a vendorized type through another embedded
+// type.
+type Deep struct {
+	Wrapper
+}
+
+// Service embeds a vendorized interface, whose methods are promoted.
+type Service interface {
+	a.Doer
+}
+
+type conn struct{}
+
+func (conn) Close() error { return nil }
+
+type closer struct {
+	conn
+}
+
+// Closer only promotes methods that use standard types.
+type Closer struct {
+	closer
+}
@@ -0,0 +1,13 @@
+package extests_test
+
+import (
+	"a"
+
+	"github.com/depbleed/go/examples/extests"
+)
+
+// Helper is an exported helper of the external test package.
+func Helper() a.String { return "" }
+
+// Open doesn't leak: the types of the tested package are not external.
+func Open() extests.Handle { return extests.Handle{} }
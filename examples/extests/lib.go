@@ -0,0 +1,9 @@
+package extests
+
+import "a"
+
+// Handle is a type of the package.
+type Handle struct{}
+
+// Value leaks a vendorized type.
+func Value() a.Int { return 0 }
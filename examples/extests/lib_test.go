@@ -0,0 +1,6 @@
+package extests
+
+import "a"
+
+// Fixture is an exported helper declared with the tests of the package.
+func Fixture() a.Bool { return false }
@@ -0,0 +1,7 @@
+// Package testutil is only built with the tests.
+package testutil
+
+import "a"
+
+// Helper is an exported test helper.
+func Helper() a.Int { return 0 }
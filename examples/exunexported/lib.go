@@ -0,0 +1,37 @@
+package exunexported
+
+import "a"
+
+type impl struct {
+	X a.Int
+	y a.Int
+}
+
+func (impl) M() a.Time { return a.Time{} }
+
+// New returns an unexported type, whose exported field is still accessible.
+func New() *impl { return nil }
+
+type inner struct {
+	W a.Int
+}
+
+// T promotes the exported fields of an unexported type.
+type T struct {
+	inner
+}
+
+type node struct {
+	Next *node
+	V    a.Bool
+}
+
+// List returns a recursive unexported type.
+func List() *node { return nil }
+
+// unused is never reached from the exported API.
+type unused struct {
+	Z a.Int
+}
+
+func (unused) M() a.Int { return 0 }
@@ -0,0 +1,31 @@
+package exwrap
+
+import "a"
+
+// Service uses a vendorized client.
+type Service struct {
+	Client *a.Client
+}
+
+// NewClient returns a vendorized client.
+func NewClient() *a.Client {
+	return a.New()
+}
+
+// Close closes the client of the service.
+func (s Service) Close() error {
+	return s.Client.Close()
+}
+
+// DefaultConfig returns a vendorized configuration.
+func DefaultConfig() a.Config {
+	return a.Config{Name: "default"}
+}
+
+// NewCounter returns a vendorized counter that was incremented once.
+func NewCounter() a.Counter {
+	var c a.Counter
+	c.Increment()
+
+	return c
+}
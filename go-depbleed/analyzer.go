@@ -0,0 +1,112 @@
+package depbleed
+
+import (
+	"go/build"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports dependency bleeding in a package.
+//
+// It allows depbleed to run through any analysis driver, like `go vet
+// -vettool` or gopls.
+var Analyzer = &analysis.Analyzer{
+	Name: "depbleed",
+	Doc:  "report exported identifiers whose types bleed dependency types",
+	URL:  "https://github.com/depbleed/go",
+	Run:  runAnalyzer,
+}
+
+var (
+	analyzerUseVCSRoot bool
+	analyzerAllow      string
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&analyzerUseVCSRoot, "use-vcs-root", false, "use VCS root as package root")
+	Analyzer.Flags.StringVar(&analyzerAllow, "allow", "", "comma-separated package patterns whose types are treated as standard types")
+}
+
+// GetPassPackageInfo returns information about the package analyzed by the
+// specified pass.
+func GetPassPackageInfo(pass *analysis.Pass, options ...Option) (PackageInfo, error) {
+	info := PackageInfo{
+		Package: pass.Pkg,
+		Info:    *pass.TypesInfo,
+		Fset:    pass.Fset,
+		Files:   pass.Files,
+	}
+
+	if len(pass.Files) > 0 {
+		info.Dir = filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+	}
+
+	if pass.Module != nil && pass.Module.Path != "" {
+		module, err := FindModule(info.Dir)
+
+		if err != nil {
+			return PackageInfo{}, err
+		}
+
+		if module == nil || module.Path != pass.Module.Path {
+			module = &Module{Path: pass.Module.Path}
+		}
+
+		info.Module = module
+	}
+
+	for _, option := range options {
+		if err := option.apply(&info); err != nil {
+			return PackageInfo{}, err
+		}
+	}
+
+	return info, nil
+}
+
+func runAnalyzer(pass *analysis.Pass) (interface{}, error) {
+	var options []Option
+
+	if analyzerUseVCSRoot {
+		options = append(options, UseVCSRootOption(build.Default.GOPATH))
+	}
+
+	if analyzerAllow != "" {
+		options = append(options, AllowPackagesOption(strings.Split(analyzerAllow, ",")...))
+	}
+
+	if len(pass.Files) > 0 {
+		config, err := FindConfig(filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if config != nil {
+			options = append(options, ConfigOption(*config))
+		}
+	}
+
+	info, err := GetPassPackageInfo(pass, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leak := range info.Leaks() {
+		pass.Report(analysis.Diagnostic{
+			Pos:            leak.Object.Pos(),
+			Category:       leak.Kind().String(),
+			Message:        leak.Error(),
+			SuggestedFixes: leak.SuggestedFixes,
+		})
+	}
+
+	for _, suppression := range info.UnusedSuppressions() {
+		pass.Reportf(suppression.pos, "unused %s directive", suppressionDirective)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,18 @@
+package depbleed
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	fixturesGoPath, _ := filepath.Abs("./fixtures/gopath")
+	analysistest.Run(t, fixturesGoPath, Analyzer, "leaky")
+}
+
+func TestAnalyzerSuggestedFixes(t *testing.T) {
+	fixturesGoPath, _ := filepath.Abs("./fixtures/gopath")
+	analysistest.RunWithSuggestedFixes(t, fixturesGoPath, Analyzer, "extract")
+}
@@ -0,0 +1,224 @@
+package depbleed
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// BaselineEntry identifies a leak regardless of its position, so that it
+// remains the same when the code around it changes.
+type BaselineEntry struct {
+	// Package is the path of the leaking package.
+	Package string `json:"package"`
+	// Object is the path of the leaking object within its package. See
+	// GetObjectPath.
+	Object string `json:"object"`
+	// Type is the offending type, qualified by its package path.
+	Type string `json:"type"`
+}
+
+// GetBaselineEntry returns the baseline entry of a leak.
+func GetBaselineEntry(leak Leak) BaselineEntry {
+	entry := BaselineEntry{
+		Object: GetObjectPath(leak.Object),
+		Type:   types.TypeString(leak.Type(), nil),
+	}
+
+	if pkg := leak.Object.Pkg(); pkg != nil {
+		entry.Package = pkg.Path()
+	}
+
+	return entry
+}
+
+// GetObjectPath returns the path of an object within its package: its name
+// for package-level objects, or the path to it from a package-level object
+// otherwise, like `T.M` for a method or `T.F` for a field.
+func GetObjectPath(obj types.Object) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+
+	scope := obj.Pkg().Scope()
+
+	if scope.Lookup(obj.Name()) == obj {
+		return obj.Name()
+	}
+
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			t := recv.Type()
+
+			if pointer, ok := t.(*types.Pointer); ok {
+				t = pointer.Elem()
+			}
+
+			if named, ok := t.(*types.Named); ok {
+				return fmt.Sprintf("%s.%s", named.Obj().Name(), obj.Name())
+			}
+		}
+	}
+
+	for _, name := range scope.Names() {
+		t := scope.Lookup(name).Type()
+
+		if _, ok := scope.Lookup(name).(*types.TypeName); ok {
+			t = t.Underlying()
+		}
+
+		if p := findMemberPath(t, obj); p != "" {
+			return fmt.Sprintf("%s.%s", name, p)
+		}
+	}
+
+	return obj.Name()
+}
+
+// findMemberPath returns the path to a field or method declared within a
+// type literal, or an empty string if it is not declared there.
+func findMemberPath(t types.Type, obj types.Object) string {
+	switch t := t.(type) {
+	case *types.Pointer:
+		return findMemberPath(t.Elem(), obj)
+	case *types.Slice:
+		return findMemberPath(t.Elem(), obj)
+	case *types.Array:
+		return findMemberPath(t.Elem(), obj)
+	case *types.Chan:
+		return findMemberPath(t.Elem(), obj)
+	case *types.Map:
+		if p := findMemberPath(t.Key(), obj); p != "" {
+			return p
+		}
+
+		return findMemberPath(t.Elem(), obj)
+	case *types.Signature:
+		for _, vars := range []*types.Tuple{t.Params(), t.Results()} {
+			for j := 0; j < vars.Len(); j++ {
+				if p := findMemberPath(vars.At(j).Type(), obj); p != "" {
+					return p
+				}
+			}
+		}
+	case *types.Struct:
+		for j := 0; j < t.NumFields(); j++ {
+			field := t.Field(j)
+
+			if field == obj {
+				return field.Name()
+			}
+
+			if p := findMemberPath(field.Type(), obj); p != "" {
+				return fmt.Sprintf("%s.%s", field.Name(), p)
+			}
+		}
+	case *types.Interface:
+		for j := 0; j < t.NumExplicitMethods(); j++ {
+			if method := t.ExplicitMethod(j); method == obj {
+				return method.Name()
+			}
+		}
+	}
+
+	return ""
+}
+
+// Baseline records known leaks, so that only new leaks are reported.
+type Baseline struct {
+	Entries []BaselineEntry `json:"leaks"`
+}
+
+// NewBaseline returns a baseline that records the specified leaks.
+func NewBaseline(leaks Leaks) Baseline {
+	baseline := Baseline{Entries: []BaselineEntry{}}
+	seen := map[BaselineEntry]bool{}
+
+	for _, leak := range leaks {
+		entry := GetBaselineEntry(leak)
+
+		if !seen[entry] {
+			seen[entry] = true
+			baseline.Entries = append(baseline.Entries, entry)
+		}
+	}
+
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		a, b := baseline.Entries[i], baseline.Entries[j]
+
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+
+		if a.Object != b.Object {
+			return a.Object < b.Object
+		}
+
+		return a.Type < b.Type
+	})
+
+	return baseline
+}
+
+// ReadBaseline reads the baseline file at the specified path.
+func ReadBaseline(filename string) (*Baseline, error) {
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read baseline file: %s", err)
+	}
+
+	var baseline Baseline
+
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("cannot parse baseline file \"%s\": %s", filename, err)
+	}
+
+	return &baseline, nil
+}
+
+// Write writes the baseline as indented JSON.
+func (b Baseline) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(b); err != nil {
+		return fmt.Errorf("cannot write baseline: %s", err)
+	}
+
+	return nil
+}
+
+// Contains checks whether the baseline records the specified leak.
+func (b Baseline) Contains(leak Leak) bool {
+	entry := GetBaselineEntry(leak)
+
+	for _, e := range b.Entries {
+		if e == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fixed returns the entries of the specified package that the specified
+// leaks of that package don't match anymore.
+func (b Baseline) Fixed(packagePath string, leaks Leaks) (result []BaselineEntry) {
+	current := map[BaselineEntry]bool{}
+
+	for _, leak := range leaks {
+		current[GetBaselineEntry(leak)] = true
+	}
+
+	for _, entry := range b.Entries {
+		if entry.Package == packagePath && !current[entry] {
+			result = append(result, entry)
+		}
+	}
+
+	return
+}
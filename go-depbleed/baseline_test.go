@@ -0,0 +1,103 @@
+package depbleed
+
+import (
+	"bytes"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetObjectPath(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exliteral")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	var paths []string
+
+	for _, leak := range info.Leaks() {
+		paths = append(paths, GetObjectPath(leak.Object))
+	}
+
+	expected := []string{"A", "B", "C", "E"}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v but got %v", expected, paths)
+	}
+
+	field := info.Package.Scope().Lookup("A").Type().(*types.Struct).Field(0)
+
+	if value := GetObjectPath(field); value != "A.X" {
+		t.Errorf("expected \"A.X\" but got \"%s\"", value)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exunexported")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	leaks := info.Leaks()
+	baseline := NewBaseline(leaks[:2])
+
+	dir, err := ioutil.TempDir("", "depbleed")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+
+	if err := baseline.Write(&buf); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	filename := filepath.Join(dir, "baseline.json")
+
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	read, err := ReadBaseline(filename)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if !reflect.DeepEqual(*read, baseline) {
+		t.Errorf("expected %v but got %v", baseline, *read)
+	}
+
+	if !read.Contains(leaks[0]) || !read.Contains(leaks[1]) {
+		t.Error("expected the baseline to contain the first leaks")
+	}
+
+	if read.Contains(leaks[2]) {
+		t.Error("expected the baseline not to contain the last leak")
+	}
+
+	fixed := read.Fixed(info.Package.Path(), leaks[1:])
+	expected := []BaselineEntry{GetBaselineEntry(leaks[0])}
+
+	if !reflect.DeepEqual(fixed, expected) {
+		t.Errorf("expected %v but got %v", expected, fixed)
+	}
+
+	if fixed := read.Fixed("other/package", nil); len(fixed) != 0 {
+		t.Errorf("expected no fixed entries but got %v", fixed)
+	}
+}
+
+func TestReadBaselineUnexisting(t *testing.T) {
+	if _, err := ReadBaseline("fixtures/unexisting.json"); err == nil {
+		t.Error("expected an error")
+	}
+}
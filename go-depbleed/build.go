@@ -0,0 +1,116 @@
+package depbleed
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// BuildConfiguration represents a configuration packages are built for.
+//
+// The exported API of a package can differ between configurations, through
+// files that are specific to an operating system or that have build
+// constraints.
+type BuildConfiguration struct {
+	// GOOS is the target operating system. When empty, the one of the Go
+	// toolchain in use is used.
+	GOOS string
+	// GOARCH is the target architecture. When empty, the one of the Go
+	// toolchain in use is used.
+	GOARCH string
+	// Tags are the additional build tags.
+	Tags []string
+}
+
+// ParsePlatform parses a platform, like `linux/amd64`, into a build
+// configuration.
+func ParsePlatform(s string) (BuildConfiguration, error) {
+	parts := strings.Split(s, "/")
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return BuildConfiguration{}, fmt.Errorf("invalid platform \"%s\": expected os/arch", s)
+	}
+
+	return BuildConfiguration{GOOS: parts[0], GOARCH: parts[1]}, nil
+}
+
+// String returns the platform of the configuration, followed by its build
+// tags, like `linux/amd64 tags=integration`.
+func (c BuildConfiguration) String() string {
+	var parts []string
+
+	if c.GOOS != "" || c.GOARCH != "" {
+		parts = append(parts, c.GOOS+"/"+c.GOARCH)
+	}
+
+	if len(c.Tags) > 0 {
+		parts = append(parts, "tags="+strings.Join(c.Tags, ","))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+type buildConfigurationOption struct {
+	configuration BuildConfiguration
+}
+
+// BuildConfigurationOption returns an option that type-checks packages for
+// the specified build configuration.
+//
+// Packages are not compiled: any configuration supported by the Go toolchain
+// can be analyzed. Leaks are annotated with the configuration.
+func BuildConfigurationOption(configuration BuildConfiguration) Option {
+	return buildConfigurationOption{configuration: configuration}
+}
+
+func (o buildConfigurationOption) configure(config *packages.Config) {
+	if config.Env == nil {
+		config.Env = os.Environ()
+	}
+
+	if o.configuration.GOOS != "" {
+		config.Env = append(config.Env, "GOOS="+o.configuration.GOOS)
+	}
+
+	if o.configuration.GOARCH != "" {
+		config.Env = append(config.Env, "GOARCH="+o.configuration.GOARCH)
+	}
+
+	if len(o.configuration.Tags) > 0 {
+		config.BuildFlags = append(config.BuildFlags, "-tags="+strings.Join(o.configuration.Tags, ","))
+	}
+}
+
+func (o buildConfigurationOption) apply(i *PackageInfo) error {
+	configuration := o.configuration
+	i.Configuration = &configuration
+
+	return nil
+}
+
+// MergeLeaks merges the leaks found in several build configurations.
+//
+// Leaks of the same object, at the same position and with the same message,
+// are merged into the first one, that gets the configurations of all of them.
+// The order of the leaks is kept.
+func MergeLeaks(leaks Leaks) (result Leaks) {
+	indexes := map[string]int{}
+
+	for _, leak := range leaks {
+		key := fmt.Sprintf("%s: %s", leak.Position, leak)
+
+		if index, ok := indexes[key]; ok {
+			result[index].Configurations = append(result[index].Configurations, leak.Configurations...)
+
+			continue
+		}
+
+		leak.Configurations = append([]BuildConfiguration(nil), leak.Configurations...)
+		indexes[key] = len(result)
+		result = append(result, leak)
+	}
+
+	return
+}
@@ -0,0 +1,116 @@
+package depbleed
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlatform(t *testing.T) {
+	testCases := []struct {
+		Value    string
+		Expected *BuildConfiguration
+	}{
+		{
+			Value:    "linux/amd64",
+			Expected: &BuildConfiguration{GOOS: "linux", GOARCH: "amd64"},
+		},
+		{
+			Value: "linux",
+		},
+		{
+			Value: "linux/",
+		},
+		{
+			Value: "linux/amd64/v3",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Value, func(t *testing.T) {
+			value, err := ParsePlatform(testCase.Value)
+
+			if testCase.Expected == nil {
+				if err == nil {
+					t.Errorf("expected an error but got: %v", value)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected no error but got: %s", err)
+				}
+
+				if !reflect.DeepEqual(value, *testCase.Expected) {
+					t.Errorf("expected \"%v\" but got \"%v\"", *testCase.Expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildConfigurationString(t *testing.T) {
+	testCases := []struct {
+		Configuration BuildConfiguration
+		Expected      string
+	}{
+		{
+			Configuration: BuildConfiguration{GOOS: "linux", GOARCH: "amd64"},
+			Expected:      "linux/amd64",
+		},
+		{
+			Configuration: BuildConfiguration{GOOS: "windows", GOARCH: "amd64", Tags: []string{"a", "b"}},
+			Expected:      "windows/amd64 tags=a,b",
+		},
+		{
+			Configuration: BuildConfiguration{Tags: []string{"a"}},
+			Expected:      "tags=a",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if value := testCase.Configuration.String(); value != testCase.Expected {
+			t.Errorf("expected \"%s\" but got \"%s\"", testCase.Expected, value)
+		}
+	}
+}
+
+func TestMergeLeaks(t *testing.T) {
+	var leaks Leaks
+
+	configurations := []BuildConfiguration{
+		{GOOS: "linux", GOARCH: "amd64", Tags: []string{"integration"}},
+		{GOOS: "windows", GOARCH: "amd64"},
+		{GOOS: "darwin", GOARCH: "arm64"},
+	}
+
+	for _, configuration := range configurations {
+		info, err := GetPackageInfo("github.com/depbleed/go/examples/explatform", BuildConfigurationOption(configuration))
+
+		if err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+
+		leaks = append(leaks, info.Leaks()...)
+	}
+
+	values := map[string][]string{}
+
+	for _, leak := range MergeLeaks(leaks) {
+		var platforms []string
+
+		for _, configuration := range leak.Configurations {
+			platforms = append(platforms, configuration.GOOS)
+		}
+
+		values[leak.Error()] = platforms
+	}
+
+	expected := map[string][]string{
+		"Common: function result 0 is an external type: a.Int is a vendorized type from github.com/depbleed/go/examples/explatform/vendor/a":      {"linux", "windows", "darwin"},
+		"Endpoint: function result 0 is an external type: a.String is a vendorized type from github.com/depbleed/go/examples/explatform/vendor/a": {"linux"},
+		"Handle: function result 0 is an external type: a.Int is a vendorized type from github.com/depbleed/go/examples/explatform/vendor/a":      {"linux"},
+		"Handle: function result 0 is an external type: a.Bool is a vendorized type from github.com/depbleed/go/examples/explatform/vendor/a":     {"windows"},
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected \"%v\" but got \"%v\"", expected, values)
+	}
+}
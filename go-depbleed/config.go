@@ -0,0 +1,231 @@
+package depbleed
+
+import (
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ConfigFilename is the name of depbleed configuration files.
+const ConfigFilename = ".depbleed.yaml"
+
+// Config represents a depbleed configuration file.
+//
+// Directories and relative package patterns in a configuration file are
+// relative to the directory that contains it.
+type Config struct {
+	// Allow lists package patterns whose types are treated as standard types.
+	// See MatchPackagePattern for the pattern syntax.
+	Allow []string `yaml:"allow"`
+	// Roots lists the directories whose packages are considered part of the
+	// package root. When empty, the package root is determined as usual.
+	Roots []string `yaml:"roots"`
+	// Exclude lists package patterns, like `foo/...`, that are not analyzed.
+	Exclude []string `yaml:"exclude"`
+	// Severity maps leak kinds, like `third-party`, to their severity level:
+	// `error`, `warning` or `info`.
+	Severity map[string]string `yaml:"severity"`
+	// Format is the default output format of the depbleed command.
+	Format string `yaml:"format"`
+	// Dir is the directory that contains the configuration file.
+	Dir string `yaml:"-"`
+}
+
+// ReadConfig reads the configuration file at the specified path.
+func ReadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %s", err)
+	}
+
+	var config Config
+
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("cannot parse configuration file \"%s\": %s", filename, err)
+	}
+
+	if config.Dir, err = filepath.Abs(filepath.Dir(filename)); err != nil {
+		return nil, fmt.Errorf("cannot determine configuration directory: %s", err)
+	}
+
+	return &config, nil
+}
+
+// FindConfig finds the configuration file that applies to the specified
+// directory.
+//
+// The directory and all its parents are searched for a configuration file.
+// If none is found, a nil configuration is returned with no error.
+func FindConfig(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine absolute path for \"%s\": %s", dir, err)
+	}
+
+	for {
+		filename := filepath.Join(dir, ConfigFilename)
+
+		if _, err := os.Stat(filename); err == nil {
+			return ReadConfig(filename)
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+}
+
+// resolve resolves a path relative to the configuration directory.
+func (c Config) resolve(p string) string {
+	p = filepath.FromSlash(p)
+
+	if filepath.IsAbs(p) {
+		return p
+	}
+
+	return filepath.Join(c.Dir, p)
+}
+
+type configOption struct {
+	config Config
+}
+
+// ConfigOption returns an option that applies the specified configuration.
+func ConfigOption(config Config) Option {
+	return configOption{config: config}
+}
+
+func (o configOption) apply(i *PackageInfo) error {
+	if err := AllowPackagesOption(o.config.Allow...).apply(i); err != nil {
+		return err
+	}
+
+	for name, level := range o.config.Severity {
+		kind, err := ParseLeakKind(name)
+
+		if err != nil {
+			return fmt.Errorf("invalid severity: %s", err)
+		}
+
+		severity, err := ParseSeverity(level)
+
+		if err != nil {
+			return fmt.Errorf("invalid severity for %s leaks: %s", kind, err)
+		}
+
+		if err := SeverityOption(kind, severity).apply(i); err != nil {
+			return err
+		}
+	}
+
+	for _, root := range o.config.Roots {
+		packagePath, err := i.getDirPackagePath(o.config.resolve(root))
+
+		if err != nil {
+			return fmt.Errorf("invalid root \"%s\": %s", root, err)
+		}
+
+		i.Roots = append(i.Roots, packagePath)
+	}
+
+	for _, pattern := range o.config.Exclude {
+		if isFilePath(pattern) {
+			packagePath, err := i.getDirPackagePath(o.config.resolve(strings.TrimSuffix(pattern, "...")))
+
+			if err != nil {
+				return fmt.Errorf("invalid exclusion \"%s\": %s", pattern, err)
+			}
+
+			if strings.HasSuffix(pattern, "...") {
+				packagePath = path.Join(packagePath, "...")
+			}
+
+			pattern = packagePath
+		}
+
+		if MatchPackagePattern(pattern, i.importPath()) {
+			i.Excluded = true
+		}
+	}
+
+	return nil
+}
+
+// getDirPackagePath returns the package path for the specified directory.
+//
+// The directory is resolved within the package module, if any, or within the
+// GOPATH otherwise.
+func (i PackageInfo) getDirPackagePath(dir string) (string, error) {
+	if i.Module != nil && i.Module.Dir != "" {
+		return i.Module.ImportPath(dir)
+	}
+
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		packagePath, err := filepath.Rel(filepath.Join(gopath, "src"), dir)
+
+		if err == nil && packagePath != ".." && !strings.HasPrefix(packagePath, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(packagePath), nil
+		}
+	}
+
+	return "", fmt.Errorf("directory \"%s\" is neither in a module nor in GOPATH", dir)
+}
+
+// PackagePattern is a compiled package pattern.
+//
+// See MatchPackagePattern for the pattern syntax.
+type PackagePattern struct {
+	pattern string
+	expr    *regexp.Regexp
+}
+
+// CompilePackagePattern compiles a package pattern.
+func CompilePackagePattern(pattern string) PackagePattern {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.Replace(expr, `\.\.\.`, `.*`, -1)
+	expr = strings.Replace(expr, `\*`, `[^/]*`, -1)
+	expr = strings.Replace(expr, `\?`, `[^/]`, -1)
+
+	if strings.HasSuffix(expr, `/.*`) {
+		expr = strings.TrimSuffix(expr, `/.*`) + `(/.*)?`
+	}
+
+	// Every other character is quoted, so the expression is always valid.
+	return PackagePattern{pattern: pattern, expr: regexp.MustCompile("^" + expr + "$")}
+}
+
+// Match checks whether a package path matches the pattern.
+func (p PackagePattern) Match(packagePath string) bool {
+	return p.expr.MatchString(packagePath)
+}
+
+// String returns the pattern as written.
+func (p PackagePattern) String() string {
+	return p.pattern
+}
+
+// MatchPackagePattern checks whether a package path matches a pattern.
+//
+// Like with the go tool, `...` in a pattern matches any string, and a pattern
+// that ends with `/...` also matches the package before it. Patterns can also
+// use glob wildcards: `*` matches any sequence of characters but `/` and `?`
+// matches any single character but `/`.
+//
+// Patterns that are matched repeatedly are better compiled once with
+// CompilePackagePattern.
+func MatchPackagePattern(pattern string, p string) bool {
+	return CompilePackagePattern(pattern).Match(p)
+}
@@ -0,0 +1,164 @@
+package depbleed
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindConfig(t *testing.T) {
+	dir, _ := filepath.Abs("../examples/exconfig")
+	config, err := FindConfig("../examples/exconfig/excluded")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if config == nil {
+		t.Fatal("expected a configuration")
+	}
+
+	expected := Config{
+		Allow:   []string{"github.com/depbleed/go/go-depbleed"},
+		Roots:   []string{".."},
+		Exclude: []string{"./excluded/..."},
+		Format:  "text",
+		Dir:     dir,
+	}
+
+	if !reflect.DeepEqual(*config, expected) {
+		t.Errorf("expected %v but got %v", expected, *config)
+	}
+}
+
+func TestFindConfigNoConfig(t *testing.T) {
+	config, err := FindConfig("/")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if config != nil {
+		t.Errorf("expected no configuration but got: %v", *config)
+	}
+}
+
+func TestReadConfigUnexisting(t *testing.T) {
+	_, err := ReadConfig("./fixtures/unexisting.yaml")
+
+	if err == nil {
+		t.Error("expected an error but didn't get one")
+	}
+}
+
+func TestConfigOption(t *testing.T) {
+	config, err := FindConfig("../examples/exconfig")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	infos, err := GetPackageInfos([]string{
+		"github.com/depbleed/go/examples/exconfig",
+		"github.com/depbleed/go/examples/exconfig/excluded",
+	}, ConfigOption(*config))
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	expectedRoots := []string{"github.com/depbleed/go/examples"}
+
+	if !reflect.DeepEqual(infos[0].GetRoots(), expectedRoots) {
+		t.Errorf("expected %v but got %v", expectedRoots, infos[0].GetRoots())
+	}
+
+	if leaks := infos[0].Leaks(); len(leaks) != 1 {
+		t.Errorf("expected 1 leak but got: %v", leaks)
+	}
+
+	if !infos[1].Excluded {
+		t.Error("expected package to be excluded")
+	}
+
+	if leaks := infos[1].Leaks(); len(leaks) != 0 {
+		t.Errorf("expected no leaks but got: %v", leaks)
+	}
+
+	if suppressions := infos[1].GetSuppressions(); len(suppressions) != 1 {
+		t.Errorf("expected 1 suppression but got %d", len(suppressions))
+	}
+
+	if suppressions := infos[1].UnusedSuppressions(); len(suppressions) != 0 {
+		t.Errorf("expected no unused suppressions but got: %v", suppressions)
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	testCases := []struct {
+		Pattern  string
+		Package  string
+		Expected bool
+	}{
+		{
+			Pattern:  "foo/bar",
+			Package:  "foo/bar",
+			Expected: true,
+		},
+		{
+			Pattern:  "foo/bar",
+			Package:  "foo/bar/baz",
+			Expected: false,
+		},
+		{
+			Pattern:  "foo/...",
+			Package:  "foo",
+			Expected: true,
+		},
+		{
+			Pattern:  "foo/...",
+			Package:  "foo/bar/baz",
+			Expected: true,
+		},
+		{
+			Pattern:  "foo/...",
+			Package:  "foobar",
+			Expected: false,
+		},
+		{
+			Pattern:  "foo/.../baz",
+			Package:  "foo/bar/baz",
+			Expected: true,
+		},
+		{
+			Pattern:  "foo.bar",
+			Package:  "fooxbar",
+			Expected: false,
+		},
+		{
+			Pattern:  "foo/*/baz",
+			Package:  "foo/bar/baz",
+			Expected: true,
+		},
+		{
+			Pattern:  "foo/*",
+			Package:  "foo/bar/baz",
+			Expected: false,
+		},
+		{
+			Pattern:  "foo/ba?",
+			Package:  "foo/bar",
+			Expected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Pattern+"-"+testCase.Package, func(t *testing.T) {
+			value := MatchPackagePattern(testCase.Pattern, testCase.Package)
+
+			if value != testCase.Expected {
+				t.Errorf("expected %t but got %t", testCase.Expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package depbleed
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GetUpstreamPackagePath returns the path of a package as published upstream,
+// without the vendor directories it is copied in.
+func GetUpstreamPackagePath(p string) string {
+	if index := strings.LastIndex(p, "/vendor/"); index >= 0 {
+		return p[index+len("/vendor/"):]
+	}
+
+	return strings.TrimPrefix(p, "vendor/")
+}
+
+// GetUpstreamTypeName returns the name of a type as published upstream, with
+// package paths that don't include vendor directories.
+func GetUpstreamTypeName(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return GetUpstreamPackagePath(pkg.Path())
+	})
+}
+
+// GetVendoredPackagePaths returns the paths of the packages vendored within
+// the specified directory, at any depth.
+//
+// Nested vendor directories only exist in GOPATH mode, so the directory must
+// be in the GOPATH.
+func GetVendoredPackagePaths(gopath string, path string) ([]string, error) {
+	path, err := filepath.Abs(strings.TrimSuffix(path, "..."))
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine absolute path for \"%s\": %s", path, err)
+	}
+
+	for _, p := range filepath.SplitList(gopath) {
+		root := filepath.Join(p, "src")
+
+		if rel, err := filepath.Rel(root, path); err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		packages := map[string]bool{}
+
+		err := filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				// Like the go tool, hidden, testdata and `_` directories are ignored.
+				if filename != path && (isHidden(info.Name()) || info.Name() == "testdata" || strings.HasPrefix(info.Name(), "_")) {
+					return filepath.SkipDir
+				}
+			} else if filepath.Ext(filename) == ".go" {
+				packagePath, _ := filepath.Rel(root, filepath.Dir(filename))
+				packagePath = filepath.ToSlash(packagePath)
+
+				if strings.Contains(packagePath, "/vendor/") {
+					packages[packagePath] = true
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to walk through \"%s\": %s", path, err)
+		}
+
+		result := []string{}
+
+		for packagePath := range packages {
+			result = append(result, packagePath)
+		}
+
+		sort.Strings(result)
+
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("path \"%s\" is not in GOPATH (%s)", path, gopath)
+}
+
+// Conflict represents leaks of the same upstream type from different vendored
+// copies. Those copies are distinct types, so a value returned by one leaking
+// package cannot be used with another.
+type Conflict struct {
+	// Type is the upstream name of the conflicting type, like `lib-foo.Foo`.
+	Type string
+	// Copies are the package qualified names of the copies of the type.
+	Copies []string
+	// Leaks are the leaks of all the copies of the type.
+	Leaks Leaks
+}
+
+// FindConflicts finds the conflicts between the leaks of the specified
+// packages.
+func FindConflicts(infos []PackageInfo) (result []Conflict) {
+	leaks := map[string]Leaks{}
+	copies := map[string]map[string]bool{}
+
+	for _, info := range infos {
+		for _, leak := range info.Leaks() {
+			name := GetUpstreamTypeName(leak.Type())
+
+			if copies[name] == nil {
+				copies[name] = map[string]bool{}
+			}
+
+			leaks[name] = append(leaks[name], leak)
+			copies[name][types.TypeString(leak.Type(), nil)] = true
+		}
+	}
+
+	for name, typeCopies := range copies {
+		if len(typeCopies) < 2 {
+			continue
+		}
+
+		conflict := Conflict{Type: name, Leaks: leaks[name]}
+
+		for typeCopy := range typeCopies {
+			conflict.Copies = append(conflict.Copies, typeCopy)
+		}
+
+		sort.Strings(conflict.Copies)
+		sort.Sort(conflict.Leaks)
+		result = append(result, conflict)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+
+	return
+}
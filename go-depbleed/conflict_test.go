@@ -0,0 +1,101 @@
+package depbleed
+
+import (
+	"go/build"
+	"reflect"
+	"testing"
+)
+
+func TestGetUpstreamPackagePath(t *testing.T) {
+	testCases := []struct {
+		Path     string
+		Expected string
+	}{
+		{
+			Path:     "foo/bar",
+			Expected: "foo/bar",
+		},
+		{
+			Path:     "foo/vendor/lib-a/vendor/lib-foo",
+			Expected: "lib-foo",
+		},
+		{
+			Path:     "vendor/lib-foo/bar",
+			Expected: "lib-foo/bar",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Path, func(t *testing.T) {
+			value := GetUpstreamPackagePath(testCase.Path)
+
+			if value != testCase.Expected {
+				t.Errorf("expected \"%s\" but got \"%s\"", testCase.Expected, value)
+			}
+		})
+	}
+}
+
+func TestGetVendoredPackagePaths(t *testing.T) {
+	values, err := GetVendoredPackagePaths(build.Default.GOPATH, "../examples/exconflict/...")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	expected := []string{
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-a",
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-a/vendor/lib-foo",
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-b",
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-b/vendor/lib-foo",
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v but got %v", expected, values)
+	}
+
+	if _, err := GetVendoredPackagePaths("/unexisting", "../examples/exconflict"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	infos, err := GetPackageInfos([]string{
+		"github.com/depbleed/go/examples/exconflict",
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-a",
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-b",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	conflicts := FindConflicts(infos)
+
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict but got: %v", conflicts)
+	}
+
+	if conflicts[0].Type != "lib-foo.Foo" {
+		t.Errorf("expected lib-foo.Foo but got %s", conflicts[0].Type)
+	}
+
+	expected := []string{
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-a/vendor/lib-foo.Foo",
+		"github.com/depbleed/go/examples/exconflict/vendor/lib-b/vendor/lib-foo.Foo",
+	}
+
+	if !reflect.DeepEqual(conflicts[0].Copies, expected) {
+		t.Errorf("expected %v but got %v", expected, conflicts[0].Copies)
+	}
+
+	var objects []string
+
+	for _, leak := range conflicts[0].Leaks {
+		objects = append(objects, leak.Object.Name())
+	}
+
+	if !reflect.DeepEqual(objects, []string{"New", "Use"}) {
+		t.Errorf("expected New and Use but got %v", objects)
+	}
+}
@@ -0,0 +1,209 @@
+package depbleed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a diff.
+const diffContext = 3
+
+type diffOpKind int
+
+const (
+	diffEqual diffOpKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a line of a diff, with its indexes in the old and new contents.
+type diffOp struct {
+	kind     diffOpKind
+	line     string
+	old, new int
+}
+
+// splitLines splits a text into lines, that keep their line terminator.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the shortest edit script between two lists of lines,
+// using the Myers algorithm.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []diffOp
+
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: diffEqual, line: a[x], old: x, new: y})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{kind: diffInsert, line: b[y], old: x, new: y})
+			} else {
+				x--
+				ops = append(ops, diffOp{kind: diffDelete, line: a[x], old: x, new: y})
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// UnifiedDiff returns the unified diff between the old and new contents of a
+// file, or an empty string if they are the same.
+func UnifiedDiff(oldName string, newName string, old string, new string) string {
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+
+	for start := 0; start < len(ops); {
+		// Look for the next change.
+		for start < len(ops) && ops[start].kind == diffEqual {
+			start++
+		}
+
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk until the changes are far enough apart.
+		end := start
+
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != diffEqual {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+
+		first, last := start-diffContext, end+diffContext
+
+		if first < 0 {
+			first = 0
+		}
+
+		if last > len(ops) {
+			last = len(ops)
+		}
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		writeHunk(&b, ops[first:last])
+		start = last
+	}
+
+	return b.String()
+}
+
+// writeHunk writes a hunk of a unified diff.
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	oldCount, newCount := 0, 0
+
+	for _, op := range ops {
+		if op.kind != diffInsert {
+			oldCount++
+		}
+
+		if op.kind != diffDelete {
+			newCount++
+		}
+	}
+
+	// Empty ranges start at the line before them.
+	oldStart, newStart := ops[0].old+1, ops[0].new+1
+
+	if oldCount == 0 {
+		oldStart--
+	}
+
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			b.WriteString(" ")
+		case diffDelete:
+			b.WriteString("-")
+		case diffInsert:
+			b.WriteString("+")
+		}
+
+		b.WriteString(op.line)
+
+		if !strings.HasSuffix(op.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package depbleed
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Old      string
+		New      string
+		Expected string
+	}{
+		{
+			Name: "same",
+			Old:  "a\nb\n",
+			New:  "a\nb\n",
+		},
+		{
+			Name:     "change",
+			Old:      "a\nb\nc\nd\ne\nf\ng\nh\n",
+			New:      "a\nb\nc\nd\nE\nf\ng\nh\n",
+			Expected: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n",
+		},
+		{
+			Name:     "insertion at the start",
+			Old:      "a\nb\n",
+			New:      "z\na\nb\n",
+			Expected: "--- old\n+++ new\n@@ -1,2 +1,3 @@\n+z\n a\n b\n",
+		},
+		{
+			Name:     "insertion in an empty file",
+			Old:      "",
+			New:      "a\n",
+			Expected: "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			Name:     "separate hunks",
+			Old:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			New:      "0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n13\n",
+			Expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+13\n",
+		},
+		{
+			Name:     "no newline at end of file",
+			Old:      "a\nb",
+			New:      "a\nc",
+			Expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			value := UnifiedDiff("old", "new", testCase.Old, testCase.New)
+
+			if value != testCase.Expected {
+				t.Errorf("expected:\n%s\nbut got:\n%s", testCase.Expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,907 @@
+package depbleed
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Wrapper represents a local type that replaces a leaked named type in the
+// exported declarations of a package.
+type Wrapper struct {
+	// Type is the leaked type.
+	Type *types.Named
+	// Name is the name of the local type.
+	Name string
+	// Methods are the methods of the leaked type that the package uses.
+	Methods []*types.Func
+	// Leaks are the leaks that the local type fixes.
+	Leaks Leaks
+	// Edits declare the local type and replace the leaked type with it.
+	Edits []analysis.TextEdit
+}
+
+// IsInterface checks whether the local type is an interface that declares the
+// used methods of the leaked type.
+//
+// Otherwise, the local type is defined from the leaked type, and has none of
+// its methods.
+func (w Wrapper) IsInterface() bool {
+	return len(w.Methods) > 0 || types.IsInterface(w.Type)
+}
+
+// GetWrappers returns the local types that fix the specified leaks of the
+// package, by replacing the leaked named types in the exported declarations.
+//
+// Only the declarations of the leaking objects are rewritten, along with the
+// values they return: the result must be reviewed, as the code that uses the
+// leaked types may need to be adapted.
+func (i PackageInfo) GetWrappers(leaks Leaks) (result []Wrapper) {
+	var wrappers []Wrapper
+
+	indexes := map[*types.TypeName]int{}
+	names := map[string]bool{}
+
+	for _, leak := range leaks {
+		named, ok := leak.Type().(*types.Named)
+
+		if !ok || named.TypeArgs().Len() > 0 {
+			continue
+		}
+
+		index, ok := indexes[named.Obj()]
+
+		if !ok {
+			index = len(wrappers)
+			indexes[named.Obj()] = index
+			wrappers = append(wrappers, Wrapper{
+				Type:    named,
+				Name:    i.getWrapperName(named, names),
+				Methods: i.getUsedMethods(named),
+			})
+		}
+
+		wrappers[index].Leaks = append(wrappers[index].Leaks, leak)
+	}
+
+	exprs := i.getDeclaredTypeExprs()
+
+	for _, wrapper := range wrappers {
+		// Interfaces can only replace the types that have the used methods.
+		if len(wrapper.Methods) > 0 && !i.hasWrapperMethods(wrapper, exprs) {
+			wrapper.Methods = nil
+		}
+
+		wrapper.Edits = i.getWrapperEdits(wrapper, exprs)
+
+		// Interfaces fall back to a defined type when the package doesn't
+		// compile with them, and wrappers are skipped when it doesn't
+		// compile with either.
+		if len(wrapper.Methods) > 0 && i.CheckEdits(wrapper.Edits) != nil {
+			wrapper.Methods = nil
+			wrapper.Edits = i.getWrapperEdits(wrapper, exprs)
+		}
+
+		if len(wrapper.Edits) > 0 && i.CheckEdits(wrapper.Edits) == nil {
+			result = append(result, wrapper)
+		}
+	}
+
+	return
+}
+
+// hasWrapperMethods checks whether the types that the local interface of a
+// wrapper replaces have all the used methods: values of the leaked type only
+// have the methods with value receivers.
+func (i PackageInfo) hasWrapperMethods(wrapper Wrapper, exprs map[token.Pos][]ast.Expr) bool {
+	valueMethods := types.NewMethodSet(wrapper.Type)
+	pointerMethods := types.NewMethodSet(types.NewPointer(wrapper.Type))
+	result := true
+
+	for _, leak := range wrapper.Leaks {
+		for _, expr := range exprs[leak.Object.Pos()] {
+			ast.Inspect(expr, func(node ast.Node) bool {
+				methodSet := valueMethods
+
+				switch node := node.(type) {
+				case *ast.StarExpr:
+					if !i.refersTo(node.X, wrapper.Type) {
+						return true
+					}
+
+					methodSet = pointerMethods
+				case *ast.SelectorExpr, *ast.Ident:
+					if !i.refersTo(node.(ast.Expr), wrapper.Type) {
+						return false
+					}
+				default:
+					return true
+				}
+
+				for _, method := range wrapper.Methods {
+					if methodSet.Lookup(method.Pkg(), method.Name()) == nil {
+						result = false
+					}
+				}
+
+				return false
+			})
+		}
+	}
+
+	return result
+}
+
+// getWrapperName returns a name for the local type of a leaked type, that is
+// not declared in the package yet.
+func (i PackageInfo) getWrapperName(named *types.Named, names map[string]bool) string {
+	return i.getLocalTypeName(nil, named, names)
+}
+
+// getLocalTypeName returns the first of the candidate names that is not
+// declared in the package yet, or else a name derived from the leaked type:
+// its name, prefixed by the name of its package, or suffixed by a number.
+func (i PackageInfo) getLocalTypeName(candidates []string, named *types.Named, names map[string]bool) string {
+	name := named.Obj().Name()
+	candidates = append(candidates[:len(candidates):len(candidates)], name)
+
+	if pkg := named.Obj().Pkg(); pkg != nil {
+		r, size := utf8.DecodeRuneInString(pkg.Name())
+		candidates = append(candidates, string(unicode.ToUpper(r))+pkg.Name()[size:]+name)
+	}
+
+	for n := 2; ; n++ {
+		for _, candidate := range candidates {
+			if i.Package.Scope().Lookup(candidate) == nil && !names[candidate] {
+				names[candidate] = true
+
+				return candidate
+			}
+		}
+
+		candidates = []string{name + strconv.Itoa(n)}
+	}
+}
+
+// getUsedMethods returns the methods of a named type that the package uses,
+// sorted by name.
+func (i PackageInfo) getUsedMethods(named *types.Named) (result []*types.Func) {
+	seen := map[*types.Func]bool{}
+
+	for _, selection := range i.Info.Selections {
+		if selection.Kind() != types.MethodVal {
+			continue
+		}
+
+		recv := selection.Recv()
+
+		if pointer, ok := recv.(*types.Pointer); ok {
+			recv = pointer.Elem()
+		}
+
+		if method, ok := selection.Obj().(*types.Func); ok && types.Identical(recv, named) && !seen[method] {
+			seen[method] = true
+			result = append(result, method)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return
+}
+
+// getDeclaredTypeExprs returns the type expressions of the declarations of
+// the package, by position of the declared objects.
+//
+// Functions are declared by their parameters and results types.
+func (i PackageInfo) getDeclaredTypeExprs() map[token.Pos][]ast.Expr {
+	result := map[token.Pos][]ast.Expr{}
+
+	for _, file := range i.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				for _, list := range []*ast.FieldList{node.Type.Params, node.Type.Results} {
+					if list == nil {
+						continue
+					}
+
+					for _, field := range list.List {
+						result[node.Name.Pos()] = append(result[node.Name.Pos()], field.Type)
+					}
+				}
+			case *ast.Field:
+				for _, name := range node.Names {
+					result[name.Pos()] = append(result[name.Pos()], node.Type)
+				}
+
+				// Embedded fields are declared by their type name.
+				if len(node.Names) == 0 {
+					if ident := getEmbeddedIdent(node.Type); ident != nil {
+						result[ident.Pos()] = append(result[ident.Pos()], node.Type)
+					}
+				}
+			case *ast.ValueSpec:
+				if node.Type != nil {
+					for _, name := range node.Names {
+						result[name.Pos()] = append(result[name.Pos()], node.Type)
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+// getEmbeddedIdent returns the type name of an embedded field.
+func getEmbeddedIdent(expr ast.Expr) *ast.Ident {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return getEmbeddedIdent(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel
+	case *ast.IndexExpr:
+		return getEmbeddedIdent(expr.X)
+	case *ast.IndexListExpr:
+		return getEmbeddedIdent(expr.X)
+	case *ast.Ident:
+		return expr
+	}
+
+	return nil
+}
+
+// refersTo checks whether an expression is the name of the specified type.
+func (i PackageInfo) refersTo(expr ast.Expr, named *types.Named) bool {
+	var ident *ast.Ident
+
+	switch expr := expr.(type) {
+	case *ast.SelectorExpr:
+		ident = expr.Sel
+	case *ast.Ident:
+		ident = expr
+	default:
+		return false
+	}
+
+	return i.Info.Uses[ident] == named.Obj()
+}
+
+// getWrapperEdits returns the edits that declare the local type of a wrapper,
+// and replace the leaked type with it in the declarations of its leaks.
+func (i PackageInfo) getWrapperEdits(wrapper Wrapper, exprs map[token.Pos][]ast.Expr) (result []analysis.TextEdit) {
+	seen := map[token.Pos]bool{}
+
+	replace := func(node ast.Node) {
+		if !seen[node.Pos()] {
+			seen[node.Pos()] = true
+			result = append(result, analysis.TextEdit{Pos: node.Pos(), End: node.End(), NewText: []byte(wrapper.Name)})
+		}
+	}
+
+	for _, leak := range wrapper.Leaks {
+		for _, expr := range exprs[leak.Object.Pos()] {
+			ast.Inspect(expr, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.StarExpr:
+					// Interfaces hold the pointers themselves.
+					if wrapper.IsInterface() && i.refersTo(node.X, wrapper.Type) {
+						replace(node)
+
+						return false
+					}
+				case *ast.SelectorExpr, *ast.Ident:
+					if i.refersTo(node.(ast.Expr), wrapper.Type) {
+						replace(node)
+					}
+
+					return false
+				}
+
+				return true
+			})
+		}
+
+		if !wrapper.IsInterface() {
+			result = append(result, i.getConversionEdits(wrapper, leak.Object)...)
+		}
+	}
+
+	if len(result) == 0 {
+		return
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Pos < result[j].Pos
+	})
+
+	return append([]analysis.TextEdit{i.getDeclarationEdit(wrapper, result[0].Pos, "the package")}, result...)
+}
+
+// getConversionEdits returns the edits that convert the values returned by a
+// function to a local type defined from the leaked type.
+func (i PackageInfo) getConversionEdits(wrapper Wrapper, obj types.Object) (result []analysis.TextEdit) {
+	decl := i.getFuncDecl(obj)
+
+	if decl == nil || decl.Type.Results == nil || decl.Body == nil {
+		return
+	}
+
+	var conversions []string
+
+	for _, field := range decl.Type.Results.List {
+		conversion := ""
+
+		switch expr := field.Type.(type) {
+		case *ast.StarExpr:
+			if i.refersTo(expr.X, wrapper.Type) {
+				conversion = "(*" + wrapper.Name + ")"
+			}
+		default:
+			if i.refersTo(expr, wrapper.Type) {
+				conversion = wrapper.Name
+			}
+		}
+
+		for n := 0; n < len(field.Names) || n == 0; n++ {
+			conversions = append(conversions, conversion)
+		}
+	}
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(node.Results) != len(conversions) {
+				return false
+			}
+
+			for index, expr := range node.Results {
+				if conversions[index] != "" {
+					result = append(result,
+						analysis.TextEdit{Pos: expr.Pos(), End: expr.Pos(), NewText: []byte(conversions[index] + "(")},
+						analysis.TextEdit{Pos: expr.End(), End: expr.End(), NewText: []byte(")")},
+					)
+				}
+			}
+		}
+
+		return true
+	})
+
+	return
+}
+
+// getFuncDecl returns the declaration of a function or method of the
+// package.
+func (i PackageInfo) getFuncDecl(obj types.Object) *ast.FuncDecl {
+	if _, ok := obj.(*types.Func); !ok {
+		return nil
+	}
+
+	for _, file := range i.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Name.Pos() == obj.Pos() {
+				return decl
+			}
+		}
+	}
+
+	return nil
+}
+
+// extractedInterface is a local interface of the methods that a function
+// calls on one of its parameters.
+type extractedInterface struct {
+	decl    *ast.FuncDecl
+	field   *ast.Field
+	param   *types.Var
+	named   *types.Named
+	methods []*types.Func
+}
+
+// key identifies the interfaces that can be shared, as they declare the same
+// methods of the same type.
+func (e extractedInterface) key() string {
+	key := e.named.String()
+
+	for _, method := range e.methods {
+		key += " " + method.Name()
+	}
+
+	return key
+}
+
+// addExtractInterfaceFixes sets the fixes of the leaks whose type is the type
+// of a leaking parameter of a function that only calls methods on it: they
+// replace that type by a local interface.
+//
+// Leaks of the same methods of the same type share the declaration of their
+// interface, so that the fixes can be applied together.
+func (i PackageInfo) addExtractInterfaceFixes(leaks Leaks) {
+	extracted := map[int]*extractedInterface{}
+	groups := map[string][]int{}
+
+	var keys []string
+
+	for index, leak := range leaks {
+		e := i.getExtractedInterface(leak.Object, leak.Path)
+
+		if e == nil {
+			continue
+		}
+
+		extracted[index] = e
+
+		if _, ok := groups[e.key()]; !ok {
+			keys = append(keys, e.key())
+		}
+
+		groups[e.key()] = append(groups[e.key()], index)
+	}
+
+	names := map[string]bool{}
+
+	for _, key := range keys {
+		first := extracted[groups[key][0]]
+
+		var candidates []string
+
+		// Single method interfaces are named after their method, like io.Closer.
+		if len(first.methods) == 1 {
+			candidates = append(candidates, strings.TrimSuffix(first.methods[0].Name(), "e")+"er")
+		}
+
+		wrapper := Wrapper{
+			Type:    first.named,
+			Name:    i.getLocalTypeName(candidates, first.named, names),
+			Methods: first.methods,
+		}
+
+		user := first.decl.Name.Name
+
+		for _, index := range groups[key] {
+			if extracted[index].decl != first.decl {
+				user = "the package"
+			}
+		}
+
+		declaration := i.getDeclarationEdit(wrapper, first.decl.Pos(), user)
+
+		for _, index := range groups[key] {
+			e := extracted[index]
+			leaks[index].SuggestedFixes = []analysis.SuggestedFix{
+				{
+					Message: fmt.Sprintf("Extract interface %s from %s", wrapper.Name, types.TypeString(e.param.Type(), i.getFileQualifier(i.getFile(e.decl.Pos())))),
+					TextEdits: []analysis.TextEdit{
+						declaration,
+						{Pos: e.field.Type.Pos(), End: e.field.Type.End(), NewText: []byte(wrapper.Name)},
+					},
+				},
+			}
+		}
+	}
+}
+
+// getExtractedInterface returns the local interface that can replace the type
+// of the leaking parameter of a function, when the function only calls methods
+// on that parameter.
+func (i PackageInfo) getExtractedInterface(obj types.Object, path LeakPath) *extractedInterface {
+	if len(path.Steps) == 0 || path.Steps[0].Kind != ParamStep {
+		return nil
+	}
+
+	named, ok := path.Type.(*types.Named)
+
+	if !ok || named.TypeArgs().Len() > 0 {
+		return nil
+	}
+
+	decl := i.getFuncDecl(obj)
+
+	if decl == nil || decl.Body == nil {
+		return nil
+	}
+
+	params := obj.Type().(*types.Signature).Params()
+
+	if path.Steps[0].Index >= params.Len() {
+		return nil
+	}
+
+	param := params.At(path.Steps[0].Index)
+	t, pointer := param.Type(), false
+
+	if p, ok := t.(*types.Pointer); ok {
+		t, pointer = p.Elem(), true
+	}
+
+	if !types.Identical(t, named) {
+		return nil
+	}
+
+	// Parameters declared together share their type.
+	var field *ast.Field
+
+	vars := map[types.Object]bool{}
+
+	for _, f := range decl.Type.Params.List {
+		for _, name := range f.Names {
+			if i.Info.Defs[name] == param {
+				field = f
+			}
+		}
+	}
+
+	if field == nil {
+		return nil
+	}
+
+	for _, name := range field.Names {
+		if v := i.Info.Defs[name]; v != nil {
+			vars[v] = true
+		}
+	}
+
+	methods := i.getMethodOnlyUses(decl.Body, vars)
+
+	if len(methods) == 0 {
+		return nil
+	}
+
+	// Values only have the methods with value receivers.
+	if !pointer {
+		methodSet := types.NewMethodSet(named)
+
+		for _, method := range methods {
+			if methodSet.Lookup(method.Pkg(), method.Name()) == nil {
+				return nil
+			}
+		}
+	}
+
+	return &extractedInterface{
+		decl:    decl,
+		field:   field,
+		param:   param,
+		named:   named,
+		methods: methods,
+	}
+}
+
+// getMethodOnlyUses returns the methods called on the specified variables
+// within a function body, sorted by name, or nil if the variables are used
+// otherwise.
+func (i PackageInfo) getMethodOnlyUses(body *ast.BlockStmt, vars map[types.Object]bool) (result []*types.Func) {
+	methodUses := map[*ast.Ident]bool{}
+	seen := map[string]bool{}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+
+		if !ok {
+			return true
+		}
+
+		ident, ok := selector.X.(*ast.Ident)
+
+		if !ok || !vars[i.Info.Uses[ident]] {
+			return true
+		}
+
+		if selection := i.Info.Selections[selector]; selection != nil && selection.Kind() == types.MethodVal {
+			methodUses[ident] = true
+
+			if method, ok := selection.Obj().(*types.Func); ok && !seen[method.Name()] {
+				seen[method.Name()] = true
+				result = append(result, method)
+			}
+		}
+
+		return true
+	})
+
+	used := true
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && vars[i.Info.Uses[ident]] && !methodUses[ident] {
+			used = false
+		}
+
+		return used
+	})
+
+	if !used {
+		return nil
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return
+}
+
+// getFile returns the file of the package that contains a position.
+func (i PackageInfo) getFile(pos token.Pos) *ast.File {
+	for _, file := range i.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// getDeclarationEdit returns the edit that declares the local type of a
+// wrapper, before the top-level declaration that contains a position. The
+// user is the code that uses the methods of the interface, if it is one.
+func (i PackageInfo) getDeclarationEdit(wrapper Wrapper, pos token.Pos, user string) analysis.TextEdit {
+	file := i.getFile(pos)
+	start := pos
+
+	for _, decl := range file.Decls {
+		if decl.Pos() <= pos && pos < decl.End() {
+			start = decl.Pos()
+
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Doc != nil {
+					start = decl.Doc.Pos()
+				}
+			case *ast.GenDecl:
+				if decl.Doc != nil {
+					start = decl.Doc.Pos()
+				}
+			}
+		}
+	}
+
+	qualifier := i.getFileQualifier(file)
+	typeName := types.TypeString(wrapper.Type, qualifier)
+
+	var b strings.Builder
+
+	if wrapper.IsInterface() {
+		fmt.Fprintf(&b, "// %s is the part of %s that %s uses.\n", wrapper.Name, typeName, user)
+		fmt.Fprintf(&b, "type %s interface {\n", wrapper.Name)
+
+		for _, method := range wrapper.Methods {
+			fmt.Fprintf(&b, "\t%s%s\n", method.Name(), strings.TrimPrefix(types.TypeString(method.Type(), qualifier), "func"))
+		}
+
+		b.WriteString("}\n\n")
+	} else {
+		fmt.Fprintf(&b, "// %s is a local type defined from %s.\n", wrapper.Name, typeName)
+		fmt.Fprintf(&b, "type %s %s\n\n", wrapper.Name, typeName)
+	}
+
+	return analysis.TextEdit{Pos: start, End: start, NewText: []byte(b.String())}
+}
+
+// getFileQualifier returns a qualifier that names packages like the imports
+// of a file do.
+func (i PackageInfo) getFileQualifier(file *ast.File) types.Qualifier {
+	names := map[string]string{}
+
+	for _, spec := range file.Imports {
+		obj := i.Info.Implicits[spec]
+
+		if spec.Name != nil {
+			obj = i.Info.Defs[spec.Name]
+		}
+
+		if name, ok := obj.(*types.PkgName); ok {
+			names[name.Imported().Path()] = name.Name()
+		}
+	}
+
+	return func(pkg *types.Package) string {
+		if pkg == i.Package {
+			return ""
+		}
+
+		if name, ok := names[pkg.Path()]; ok {
+			return name
+		}
+
+		return pkg.Name()
+	}
+}
+
+// readFile returns the content of a source file of the package, from the
+// overlay the package was loaded with, if any, or from the disk.
+//
+// An error is returned if the file changed since the package was loaded, as
+// the positions of the package wouldn't match its content anymore.
+func (i PackageInfo) readFile(filename string) ([]byte, error) {
+	content, ok := i.Overlay[filename]
+
+	if !ok {
+		var err error
+
+		if content, err = ioutil.ReadFile(filename); err != nil {
+			return nil, fmt.Errorf("cannot read \"%s\": %s", filename, err)
+		}
+	}
+
+	for _, file := range i.Files {
+		if tf := i.Fset.File(file.Pos()); tf != nil && tf.Name() == filename && tf.Size() != len(content) {
+			return nil, fmt.Errorf("\"%s\" changed since the package was loaded", filename)
+		}
+	}
+
+	return content, nil
+}
+
+// ApplyEdits applies text edits to the source files of the package they are
+// in, and returns the new formatted contents of these files, by filename.
+//
+// The edits are applied to the contents the package was loaded with.
+func (i PackageInfo) ApplyEdits(edits []analysis.TextEdit) (map[string][]byte, error) {
+	fset := i.Fset
+	files := map[string][]analysis.TextEdit{}
+
+	for _, edit := range edits {
+		filename := fset.Position(edit.Pos).Filename
+		files[filename] = append(files[filename], edit)
+	}
+
+	result := map[string][]byte{}
+
+	for filename, edits := range files {
+		content, err := i.readFile(filename)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(edits, func(i, j int) bool {
+			return edits[i].Pos < edits[j].Pos
+		})
+
+		var b bytes.Buffer
+
+		offset := 0
+
+		for _, edit := range edits {
+			start := fset.Position(edit.Pos).Offset
+			end := start
+
+			if edit.End.IsValid() {
+				end = fset.Position(edit.End).Offset
+			}
+
+			if start < offset {
+				return nil, fmt.Errorf("overlapping edits in \"%s\"", filename)
+			}
+
+			b.Write(content[offset:start])
+			b.Write(edit.NewText)
+			offset = end
+		}
+
+		b.Write(content[offset:])
+
+		formatted, err := format.Source(b.Bytes())
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot format \"%s\": %s", filename, err)
+		}
+
+		result[filename] = formatted
+	}
+
+	return result, nil
+}
+
+// packageImporter imports the packages that a package imports, by import
+// path.
+type packageImporter map[string]*types.Package
+
+func (p packageImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := p[path]; ok {
+		return pkg, nil
+	}
+
+	return nil, fmt.Errorf("package \"%s\" is not imported by the package", path)
+}
+
+// CheckEdits type-checks the package with text edits applied to its files,
+// and returns an error if it doesn't compile anymore.
+func (i PackageInfo) CheckEdits(edits []analysis.TextEdit) error {
+	contents, err := i.ApplyEdits(edits)
+
+	if err != nil {
+		return err
+	}
+
+	importer := packageImporter{"unsafe": types.Unsafe}
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+
+	for _, file := range i.Files {
+		for _, spec := range file.Imports {
+			obj := i.Info.Implicits[spec]
+
+			if spec.Name != nil && i.Info.Defs[spec.Name] != nil {
+				obj = i.Info.Defs[spec.Name]
+			}
+
+			if name, ok := obj.(*types.PkgName); ok {
+				if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+					importer[path] = name.Imported()
+				}
+			}
+		}
+
+		filename := i.Fset.Position(file.Pos()).Filename
+		content, ok := contents[filename]
+
+		if !ok {
+			if content, err = i.readFile(filename); err != nil {
+				return err
+			}
+		}
+
+		f, err := parser.ParseFile(fset, filename, content, parser.ParseComments)
+
+		if err != nil {
+			return fmt.Errorf("cannot parse \"%s\": %s", filename, err)
+		}
+
+		files = append(files, f)
+	}
+
+	config := types.Config{Importer: importer}
+
+	if _, err := config.Check(i.Package.Path(), fset, files, nil); err != nil {
+		return fmt.Errorf("the package does not compile with the edits: %s", err)
+	}
+
+	return nil
+}
+
+// CombineWrappers returns the wrappers that the package compiles with when
+// their edits are applied together, in order.
+//
+// Each wrapper compiles on its own, but not necessarily along with the others:
+// a wrapper that breaks the package along with the previous ones is skipped,
+// and an error is returned for it.
+func (i PackageInfo) CombineWrappers(wrappers []Wrapper) (result []Wrapper, errs []error) {
+	var edits []analysis.TextEdit
+
+	for _, wrapper := range wrappers {
+		combined := append(append([]analysis.TextEdit{}, edits...), wrapper.Edits...)
+
+		if err := i.CheckEdits(combined); err != nil {
+			errs = append(errs, fmt.Errorf("cannot wrap %s in %s: %s", GetTypeShortName(wrapper.Type), wrapper.Name, err))
+
+			continue
+		}
+
+		edits = combined
+		result = append(result, wrapper)
+	}
+
+	return
+}
@@ -0,0 +1,217 @@
+package depbleed
+
+import (
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestGetWrappers(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exwrap")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	wrappers := info.GetWrappers(info.Leaks())
+
+	if len(wrappers) != 3 {
+		t.Fatalf("expected 3 wrappers but got %d", len(wrappers))
+	}
+
+	if wrappers[0].Name != "Client" || !wrappers[0].IsInterface() {
+		t.Errorf("expected an interface named Client but got %s", wrappers[0].Name)
+	}
+
+	if len(wrappers[0].Methods) != 1 || wrappers[0].Methods[0].Name() != "Close" {
+		t.Errorf("expected only the Close method to be used but got: %v", wrappers[0].Methods)
+	}
+
+	if wrappers[1].Name != "Config" || wrappers[1].IsInterface() {
+		t.Errorf("expected a defined type named Config but got %s", wrappers[1].Name)
+	}
+
+	// Values don't have the methods with pointer receivers.
+	if wrappers[2].Name != "Counter" || wrappers[2].IsInterface() {
+		t.Errorf("expected a defined type named Counter but got %s", wrappers[2].Name)
+	}
+
+	var edits []analysis.TextEdit
+
+	for _, wrapper := range wrappers {
+		edits = append(edits, wrapper.Edits...)
+	}
+
+	files, err := info.ApplyEdits(edits)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	expected := `package exwrap
+
+import "a"
+
+// Client is the part of a.Client that the package uses.
+type Client interface {
+	Close() error
+}
+
+// Service uses a vendorized client.
+type Service struct {
+	Client Client
+}
+
+// NewClient returns a vendorized client.
+func NewClient() Client {
+	return a.New()
+}
+
+// Close closes the client of the service.
+func (s Service) Close() error {
+	return s.Client.Close()
+}
+
+// Config is a local type defined from a.Config.
+type Config a.Config
+
+// DefaultConfig returns a vendorized configuration.
+func DefaultConfig() Config {
+	return Config(a.Config{Name: "default"})
+}
+
+// Counter is a local type defined from a.Counter.
+type Counter a.Counter
+
+// NewCounter returns a vendorized counter that was incremented once.
+func NewCounter() Counter {
+	var c a.Counter
+	c.Increment()
+
+	return Counter(c)
+}
+`
+
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file but got %d", len(files))
+	}
+
+	for _, content := range files {
+		if string(content) != expected {
+			t.Errorf("expected:\n%s\nbut got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestCombineWrappers(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exwrap")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	wrappers := info.GetWrappers(info.Leaks())
+
+	// This wrapper compiles on its own, but declares the same name as the
+	// Client interface.
+	conflicting := Wrapper{
+		Type: wrappers[0].Type,
+		Name: "Client",
+		Edits: []analysis.TextEdit{
+			{Pos: info.Files[0].End(), NewText: []byte("\n\ntype Client struct{}\n")},
+		},
+	}
+
+	if err := info.CheckEdits(conflicting.Edits); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	combined, errs := info.CombineWrappers(append(wrappers[:2:2], conflicting, wrappers[2]))
+
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error but got: %v", errs)
+	}
+
+	if len(combined) != len(wrappers) {
+		t.Fatalf("expected %d wrappers but got %d", len(wrappers), len(combined))
+	}
+
+	for index, wrapper := range combined {
+		if wrapper.Name != wrappers[index].Name || len(wrapper.Edits) != len(wrappers[index].Edits) {
+			t.Errorf("expected wrapper %s but got %s", wrappers[index].Name, wrapper.Name)
+		}
+	}
+}
+
+func TestApplyEditsOverlay(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exwrap")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	filename := info.Fset.Position(info.Files[0].Pos()).Filename
+	content, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	// The edits must be applied to the overlay, whose offsets differ from
+	// the ones of the file on disk.
+	overlay := append([]byte("// Package exwrap is being edited.\n"), content...)
+	info, err = GetPackageInfo("github.com/depbleed/go/examples/exwrap", OverlayOption(map[string][]byte{filename: overlay}))
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	var edits []analysis.TextEdit
+
+	for _, wrapper := range info.GetWrappers(info.Leaks()) {
+		edits = append(edits, wrapper.Edits...)
+	}
+
+	if err := info.CheckEdits(edits); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	files, err := info.ApplyEdits(edits)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if value := string(files[filename]); !strings.HasPrefix(value, "// Package exwrap is being edited.\npackage exwrap\n") || !strings.Contains(value, "\tClient Client\n") {
+		t.Errorf("expected the edits to be applied to the overlay but got:\n%s", value)
+	}
+}
+
+func TestGetWrapperName(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exwrap")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	pkg := types.NewPackage("foo/a", "a")
+	names := map[string]bool{}
+
+	for _, expected := range []string{"Request", "AService", "Service2"} {
+		name := expected
+
+		if expected != "Request" {
+			name = "Service"
+		}
+
+		named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), types.NewStruct(nil, nil), nil)
+
+		if value := info.getWrapperName(named, names); value != expected {
+			t.Errorf("expected \"%s\" but got \"%s\"", expected, value)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module example.com/mod // The module path.
+
+go 1.11
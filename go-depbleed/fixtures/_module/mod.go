@@ -0,0 +1,4 @@
+package mod
+
+// Root is a type from the module root package.
+type Root struct{}
@@ -0,0 +1,6 @@
+package sub
+
+import "example.com/mod"
+
+// Exposed exposes a type from the same module.
+var Exposed mod.Root
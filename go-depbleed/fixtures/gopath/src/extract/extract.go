@@ -0,0 +1,30 @@
+package extract
+
+import "a"
+
+// Shutdown only calls a method of its parameter.
+func Shutdown(c *a.Client) error { // want `Shutdown: function argument "c" is an external type: pointer to external type: a.Client is a vendorized type from extract/vendor/a`
+	return c.Close()
+}
+
+// Send only calls methods of its parameter.
+func Send(c *a.Client, r a.Request) a.Response { // want `Send: function argument "c" is an external type: pointer to external type: a.Client is a vendorized type from extract/vendor/a`
+	defer c.Close()
+
+	return c.Do(r)
+}
+
+// Clone uses its parameter otherwise.
+func Clone(c *a.Client) a.Client { // want `Clone: function argument "c" is an external type: pointer to external type: a.Client is a vendorized type from extract/vendor/a`
+	return *c
+}
+
+// Close cannot be called on a value.
+func Close(c a.Client) error { // want `Close: function argument "c" is an external type: a.Client is a vendorized type from extract/vendor/a`
+	return c.Close()
+}
+
+// Stop only calls the same method as Shutdown.
+func Stop(c *a.Client) error { // want `Stop: function argument "c" is an external type: pointer to external type: a.Client is a vendorized type from extract/vendor/a`
+	return c.Close()
+}
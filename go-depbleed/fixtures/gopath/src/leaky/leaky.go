@@ -0,0 +1,27 @@
+package leaky
+
+import "a"
+
+// A exposes a vendorized type.
+var A a.Int // want `A: a.Int is a vendorized type from leaky/vendor/a`
+
+// B exposes a built-in type.
+var B int
+
+// C exposes a vendorized type as a function result.
+func C() a.Int { return 0 } // want `C: function result 0 is an external type: a.Int is a vendorized type from leaky/vendor/a`
+
+// Set is a generic type.
+type Set[T comparable] map[T]struct{}
+
+// D instantiates a generic type with a vendorized type.
+var D Set[a.Int] // want `D: type argument 0 of Set is a vendorized type from leaky/vendor/a`
+
+// E exposes a vendorized type through a struct literal.
+var E struct{ F a.Int } // want `E: field "F" is an external type: a.Int is a vendorized type from leaky/vendor/a`
+
+// G is a deliberate leak.
+var G a.Int //depbleed:ignore
+
+// H used to be a deliberate leak.
+var H int //depbleed:ignore // want `unused //depbleed:ignore directive`
@@ -51,6 +51,46 @@ func TestExamples(t *testing.T) {
 			LeaksCount:       1,
 			UseVCSLeaksCount: 0,
 		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exgeneric",
+			LeaksCount:       5,
+			UseVCSLeaksCount: 5,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exliteral",
+			LeaksCount:       4,
+			UseVCSLeaksCount: 4,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exignore",
+			LeaksCount:       2,
+			UseVCSLeaksCount: 2,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exinternal",
+			LeaksCount:       2,
+			UseVCSLeaksCount: 2,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exinternal/internal/user",
+			LeaksCount:       1,
+			UseVCSLeaksCount: 0,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exunexported",
+			LeaksCount:       3,
+			UseVCSLeaksCount: 3,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/expromoted",
+			LeaksCount:       5,
+			UseVCSLeaksCount: 5,
+		},
+		{
+			PackagePath:      "github.com/depbleed/go/examples/exwrap",
+			LeaksCount:       4,
+			UseVCSLeaksCount: 4,
+		},
 		{
 			PackagePath:      "github.com/depbleed/go/examples/excomplete",
 			LeaksCount:       13,
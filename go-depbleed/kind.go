@@ -0,0 +1,164 @@
+package depbleed
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// LeakKind classifies leaks.
+type LeakKind int
+
+const (
+	// VendoredLeak indicates that the offending type comes from a vendored
+	// package.
+	VendoredLeak LeakKind = iota
+	// ThirdPartyLeak indicates that the offending type comes from a
+	// non-standard package outside of the package root.
+	ThirdPartyLeak
+	// InternalLeak indicates that the offending type comes from an internal
+	// package that some importers of the leaking package cannot import.
+	InternalLeak
+	// UnexportedReachableLeak indicates that the offending type is exposed
+	// through an unexported type reachable from the exported API.
+	UnexportedReachableLeak
+	// PromotedLeak indicates that the offending type is exposed through a
+	// method promoted from an embedded field.
+	PromotedLeak
+)
+
+// LeakKinds lists all the leak kinds.
+var LeakKinds = []LeakKind{
+	VendoredLeak,
+	ThirdPartyLeak,
+	InternalLeak,
+	UnexportedReachableLeak,
+	PromotedLeak,
+}
+
+var leakKindNames = [...]string{
+	VendoredLeak:            "vendored",
+	ThirdPartyLeak:          "third-party",
+	InternalLeak:            "internal",
+	UnexportedReachableLeak: "unexported-reachable",
+	PromotedLeak:            "promoted",
+}
+
+// String returns the name of the leak kind.
+func (k LeakKind) String() string {
+	if k >= 0 && int(k) < len(leakKindNames) {
+		return leakKindNames[k]
+	}
+
+	return fmt.Sprintf("LeakKind(%d)", int(k))
+}
+
+// ParseLeakKind returns the leak kind with the specified name.
+func ParseLeakKind(s string) (LeakKind, error) {
+	for _, kind := range LeakKinds {
+		if kind.String() == s {
+			return kind, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown leak kind \"%s\"", s)
+}
+
+// Kind returns the kind of the leak path.
+//
+// Leaks through promoted methods or unexported types are classified as such,
+// whatever the offending type. Other leaks are classified according to the
+// package of the offending type.
+func (p LeakPath) Kind() LeakKind {
+	if len(p.Steps) > 0 && p.Steps[0].Kind == PromotedMethodStep {
+		return PromotedLeak
+	}
+
+	for _, step := range p.Steps {
+		switch step.Kind {
+		case FieldStep, EmbeddedFieldStep, MethodStep, UnderlyingStep:
+			if _, ok := step.Type.(*types.Named); ok {
+				return UnexportedReachableLeak
+			}
+		}
+	}
+
+	switch {
+	case p.Internal:
+		return InternalLeak
+	case p.Vendored:
+		return VendoredLeak
+	default:
+		return ThirdPartyLeak
+	}
+}
+
+// Severity is the severity level of a leak.
+type Severity int
+
+const (
+	// SeverityError is the level of leaks that make the linting fail.
+	SeverityError Severity = iota
+	// SeverityWarning is the level of leaks that are reported as warnings.
+	SeverityWarning
+	// SeverityInfo is the level of leaks that are only reported for
+	// information.
+	SeverityInfo
+)
+
+var severityNames = [...]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "info",
+}
+
+// String returns the name of the severity level.
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity returns the severity level with the specified name.
+func ParseSeverity(s string) (Severity, error) {
+	for severity, name := range severityNames {
+		if name == s {
+			return Severity(severity), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown severity \"%s\"", s)
+}
+
+type severityOption struct {
+	kind     LeakKind
+	severity Severity
+}
+
+// SeverityOption returns an option that sets the severity level of the leaks
+// of the specified kind.
+//
+// Leaks are errors by default.
+func SeverityOption(kind LeakKind, severity Severity) Option {
+	return severityOption{kind: kind, severity: severity}
+}
+
+func (o severityOption) apply(i *PackageInfo) error {
+	if i.Severities == nil {
+		i.Severities = map[LeakKind]Severity{}
+	}
+
+	i.Severities[o.kind] = o.severity
+
+	return nil
+}
+
+// GetSeverity returns the severity level of the leaks of the specified kind.
+func (i PackageInfo) GetSeverity(kind LeakKind) Severity {
+	if severity, ok := i.Severities[kind]; ok {
+		return severity
+	}
+
+	return SeverityError
+}
@@ -0,0 +1,135 @@
+package depbleed
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestParseLeakKind(t *testing.T) {
+	for _, kind := range LeakKinds {
+		value, err := ParseLeakKind(kind.String())
+
+		if err != nil {
+			t.Errorf("expected no error but got: %s", err)
+		}
+
+		if value != kind {
+			t.Errorf("expected %s but got %s", kind, value)
+		}
+	}
+
+	if _, err := ParseLeakKind("unknown"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		value, err := ParseSeverity(severity.String())
+
+		if err != nil {
+			t.Errorf("expected no error but got: %s", err)
+		}
+
+		if value != severity {
+			t.Errorf("expected %s but got %s", severity, value)
+		}
+	}
+
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestLeakPathKind(t *testing.T) {
+	pkg := types.NewPackage("foo/bar", "bar")
+	impl := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "impl", nil), types.NewStruct(nil, nil), nil)
+	testCases := []struct {
+		Name     string
+		Path     LeakPath
+		Expected LeakKind
+	}{
+		{
+			Name:     "vendored",
+			Path:     LeakPath{Vendored: true},
+			Expected: VendoredLeak,
+		},
+		{
+			Name:     "third-party",
+			Path:     LeakPath{},
+			Expected: ThirdPartyLeak,
+		},
+		{
+			Name:     "internal",
+			Path:     LeakPath{Vendored: true, Internal: true},
+			Expected: InternalLeak,
+		},
+		{
+			Name: "anonymous struct",
+			Path: LeakPath{
+				Steps:    []Step{{Kind: FieldStep, Type: types.NewStruct(nil, nil), Name: "F"}},
+				Vendored: true,
+			},
+			Expected: VendoredLeak,
+		},
+		{
+			Name: "unexported-reachable",
+			Path: LeakPath{
+				Steps: []Step{
+					{Kind: ResultStep},
+					{Kind: FieldStep, Type: impl, Name: "F"},
+				},
+				Vendored: true,
+			},
+			Expected: UnexportedReachableLeak,
+		},
+		{
+			Name: "promoted",
+			Path: LeakPath{
+				Steps: []Step{
+					{Kind: PromotedMethodStep, Name: "M"},
+					{Kind: MethodStep, Type: impl, Name: "M"},
+				},
+			},
+			Expected: PromotedLeak,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			value := testCase.Path.Kind()
+
+			if value != testCase.Expected {
+				t.Errorf("expected %s but got %s", testCase.Expected, value)
+			}
+		})
+	}
+}
+
+func TestSeverityOption(t *testing.T) {
+	info, err := GetPackageInfo(
+		"github.com/depbleed/go/examples/expromoted",
+		SeverityOption(PromotedLeak, SeverityWarning),
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if info.GetSeverity(VendoredLeak) != SeverityError {
+		t.Errorf("expected vendored leaks to be errors")
+	}
+
+	for _, leak := range info.Leaks() {
+		expected := SeverityError
+
+		if leak.Kind() == PromotedLeak {
+			expected = SeverityWarning
+		}
+
+		if leak.Severity != expected {
+			t.Errorf("expected %s to be a %s but got a %s", leak, expected, leak.Severity)
+		}
+	}
+}
@@ -4,18 +4,90 @@ import (
 	"fmt"
 	"go/token"
 	"go/types"
+
+	"golang.org/x/tools/go/analysis"
 )
 
 // Leak represents a leaking type.
 type Leak struct {
 	Object   types.Object
 	Position token.Position
-	err      error
+	// Path goes from the type of the object down to the offending type.
+	Path LeakPath
+	// Severity is the severity level of the leak, according to its kind.
+	Severity Severity
+	// Configurations are the build configurations in which the leak appears,
+	// when packages are analyzed for explicit build configurations.
+	Configurations []BuildConfiguration
+	// SuggestedFixes are the fixes of the leak that can be applied
+	// automatically, like extracting an interface for a parameter of which
+	// only methods are used.
+	SuggestedFixes []analysis.SuggestedFix
 }
 
 // Error constructs an error string.
 func (l Leak) Error() string {
-	return fmt.Sprintf("%s: %s", l.Object.Name(), l.err)
+	return fmt.Sprintf("%s: %s", l.Object.Name(), l.Path)
+}
+
+// ObjectKind returns the kind of the leaking object.
+//
+// It is one of "const", "var", "field", "func", "method", "type" or "object".
+func (l Leak) ObjectKind() string {
+	switch obj := l.Object.(type) {
+	case *types.Const:
+		return "const"
+	case *types.Var:
+		if obj.IsField() {
+			return "field"
+		}
+
+		return "var"
+	case *types.Func:
+		if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+			return "method"
+		}
+
+		return "func"
+	case *types.TypeName:
+		return "type"
+	default:
+		return "object"
+	}
+}
+
+// Kind returns the kind of the leak.
+func (l Leak) Kind() LeakKind {
+	return l.Path.Kind()
+}
+
+// Type returns the offending type, that comes from another package.
+func (l Leak) Type() types.Type {
+	return l.Path.Type
+}
+
+// PackagePath returns the path of the package the offending type comes from.
+func (l Leak) PackagePath() string {
+	return l.Path.PackagePath
+}
+
+// IsVendored checks whether the offending type comes from a vendored package.
+//
+// If the offending type is neither vendored nor internal, it is a global type.
+func (l Leak) IsVendored() bool {
+	return l.Path.Vendored
+}
+
+// IsInternal checks whether the offending type comes from an internal package
+// that some importers of the leaking package cannot import.
+func (l Leak) IsInternal() bool {
+	return l.Path.Internal
+}
+
+// Reasons returns the reasons of the leak, from the type of the leaking
+// object down to the offending type.
+func (l Leak) Reasons() []string {
+	return l.Path.Reasons()
 }
 
 // Leaks represents a slice of Leak instances.
@@ -33,7 +105,9 @@ func (slice Leaks) Less(i, j int) bool {
 			slice[i].Position.Line < slice[j].Position.Line) ||
 		(slice[i].Position.Filename == slice[j].Position.Filename &&
 			slice[i].Position.Line == slice[j].Position.Line &&
-			slice[i].Position.Column < slice[j].Position.Column)
+			slice[i].Position.Column < slice[j].Position.Column) ||
+		(slice[i].Position == slice[j].Position &&
+			slice[i].Error() < slice[j].Error())
 }
 
 // Swap swaps two elements.
@@ -1,7 +1,6 @@
 package depbleed
 
 import (
-	"errors"
 	"go/token"
 	"go/types"
 	"reflect"
@@ -11,13 +10,16 @@ import (
 
 func TestLeakError(t *testing.T) {
 	pkg := types.NewPackage("foo/bar", "bar")
-	typename := types.NewTypeName(token.NoPos, pkg, "MyType", types.NewStruct(nil, nil))
+	typename := types.NewTypeName(token.NoPos, pkg, "MyType", nil)
 	leak := Leak{
 		Object: typename,
-		err:    errors.New("fail"),
+		Path: LeakPath{
+			Type:        types.NewNamed(typename, types.NewStruct(nil, nil), nil),
+			PackagePath: pkg.Path(),
+		},
 	}
 
-	expected := "MyType: fail"
+	expected := "MyType: bar.MyType is a global type from foo/bar"
 	err := leak.Error()
 
 	if err != expected {
@@ -25,6 +27,88 @@ func TestLeakError(t *testing.T) {
 	}
 }
 
+func TestLeakDetails(t *testing.T) {
+	pkg := types.NewPackage("foo/bar", "bar")
+	vendorPkg := types.NewPackage("foo/bar/vendor/a", "a")
+	typ := types.NewNamed(types.NewTypeName(token.NoPos, vendorPkg, "Int", nil), types.Typ[types.Int], nil)
+	leak := Leak{
+		Object: types.NewVar(token.NoPos, pkg, "MyVar", types.NewPointer(typ)),
+		Path: LeakPath{
+			Steps: []Step{
+				{Kind: ElemStep, Type: types.NewPointer(typ)},
+			},
+			Type:        typ,
+			PackagePath: vendorPkg.Path(),
+			Vendored:    true,
+		},
+	}
+
+	if leak.ObjectKind() != "var" {
+		t.Errorf("expected \"var\" but got \"%s\"", leak.ObjectKind())
+	}
+
+	if leak.Type() != typ {
+		t.Errorf("expected %s but got %s", typ, leak.Type())
+	}
+
+	if leak.PackagePath() != vendorPkg.Path() {
+		t.Errorf("expected \"%s\" but got \"%s\"", vendorPkg.Path(), leak.PackagePath())
+	}
+
+	if !leak.IsVendored() {
+		t.Error("expected a vendored type")
+	}
+
+	expected := []string{
+		"pointer to external type",
+		"a.Int is a vendorized type from foo/bar/vendor/a",
+	}
+
+	if !reflect.DeepEqual(leak.Reasons(), expected) {
+		t.Errorf("expected %v but got %v", expected, leak.Reasons())
+	}
+}
+
+func TestLeakObjectKind(t *testing.T) {
+	pkg := types.NewPackage("foo/bar", "bar")
+	recv := types.NewVar(token.NoPos, pkg, "r", types.Typ[types.Int])
+	testCases := []struct {
+		Object   types.Object
+		Expected string
+	}{
+		{
+			Object:   types.NewConst(token.NoPos, pkg, "C", types.Typ[types.Int], nil),
+			Expected: "const",
+		},
+		{
+			Object:   types.NewField(token.NoPos, pkg, "F", types.Typ[types.Int], false),
+			Expected: "field",
+		},
+		{
+			Object:   types.NewFunc(token.NoPos, pkg, "F", types.NewSignatureType(nil, nil, nil, nil, nil, false)),
+			Expected: "func",
+		},
+		{
+			Object:   types.NewFunc(token.NoPos, pkg, "M", types.NewSignatureType(recv, nil, nil, nil, nil, false)),
+			Expected: "method",
+		},
+		{
+			Object:   types.NewTypeName(token.NoPos, pkg, "T", nil),
+			Expected: "type",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Expected, func(t *testing.T) {
+			value := Leak{Object: testCase.Object}.ObjectKind()
+
+			if value != testCase.Expected {
+				t.Errorf("expected \"%s\" but got \"%s\"", testCase.Expected, value)
+			}
+		})
+	}
+}
+
 func TestLeaksSort(t *testing.T) {
 	a1 := Leak{
 		Position: token.Position{Filename: "a"},
@@ -0,0 +1,540 @@
+// Package lsp implements a language server that publishes depbleed leaks as
+// diagnostics, for editors that don't go through gopls.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	depbleed "github.com/depbleed/go/go-depbleed"
+)
+
+// suppressionDirective is the directive reported when unused.
+const suppressionDirective = "//depbleed:ignore"
+
+// Server is a language server that publishes the leaks of the packages of the
+// open documents as diagnostics.
+//
+// The packages of the workspace are type-checked when the client is
+// initialized, and kept until their documents or the packages they import
+// change. The contents of the open documents are used rather than the files on
+// disk.
+type Server struct {
+	// Config is the configuration to use. When nil, the closest configuration
+	// file of the workspace root, or of the package outside of it, is used.
+	Config *depbleed.Config
+	// Options are the options the packages are loaded with.
+	Options []depbleed.Option
+
+	queue *messageQueue
+	root  string
+	// packages are the type-checked packages, by directory.
+	packages map[string]depbleed.PackageInfo
+	// paths are the paths of the packages that were type-checked, by
+	// directory, so that their importers can be told when they change.
+	paths map[string]string
+	// overlay holds the contents of the open documents, by filename.
+	overlay map[string][]byte
+}
+
+// Serve serves the protocol over the specified reader and writer, usually the
+// standard input and output, until the client exits.
+func (s *Server) Serve(r io.Reader, w io.Writer) (err error) {
+	s.queue = newMessageQueue(w)
+	s.packages = map[string]depbleed.PackageInfo{}
+	s.paths = map[string]string{}
+	s.overlay = map[string][]byte{}
+
+	// The messages that were sent are written before returning.
+	defer func() {
+		if closeErr := s.queue.close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("cannot write message: %s", closeErr)
+		}
+	}()
+
+	reader := bufio.NewReader(r)
+
+	for {
+		data, err := readMessage(reader)
+
+		// The client went away without exiting.
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("cannot read message: %s", err)
+		}
+
+		var req request
+
+		if err := json.Unmarshal(data, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: parseError, Message: err.Error()}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, responseErr := s.handle(req)
+
+		// Notifications have no response.
+		if req.ID == nil {
+			if responseErr != nil {
+				s.logError(responseErr.Message)
+			}
+
+			continue
+		}
+
+		if err := s.reply(req.ID, result, responseErr); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request or a notification.
+func (s *Server) handle(req request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		uri := params.RootURI
+
+		if uri == "" && len(params.WorkspaceFolders) > 0 {
+			uri = params.WorkspaceFolders[0].URI
+		}
+
+		if uri != "" {
+			root, err := uriToPath(uri)
+
+			if err != nil {
+				return nil, &responseError{Code: invalidParams, Message: err.Error()}
+			}
+
+			s.root = root
+		}
+
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{
+					OpenClose: true,
+					Change:    textDocumentSyncFull,
+					Save:      true,
+				},
+			},
+			ServerInfo: serverInfo{Name: "depbleed"},
+		}, nil
+	case "initialized":
+		// Warm up: later documents of the workspace are published right away.
+		if s.root != "" {
+			if _, err := s.load(s.root, "./..."); err != nil {
+				s.logError(err.Error())
+			}
+		}
+	case "shutdown":
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.update(params.TextDocument.URI, &params.TextDocument.Text, true)
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+
+		// With full sync, the last change has the whole content.
+		return nil, s.update(params.TextDocument.URI, &params.ContentChanges[len(params.ContentChanges)-1].Text, false)
+	case "textDocument/didSave":
+		var params didSaveTextDocumentParams
+
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.update(params.TextDocument.URI, nil, true)
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+
+		return nil, s.update(params.TextDocument.URI, nil, false)
+	default:
+		if req.ID != nil {
+			return nil, &responseError{Code: methodNotFound, Message: fmt.Sprintf("method \"%s\" not found", req.Method)}
+		}
+	}
+
+	return nil, nil
+}
+
+// unmarshalParams unmarshals the parameters of a request.
+func unmarshalParams(req request, params interface{}) *responseError {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return &responseError{Code: invalidParams, Message: fmt.Sprintf("invalid %s parameters: %s", req.Method, err)}
+	}
+
+	return nil
+}
+
+// update handles a change of a document: its new content, if any, replaces the
+// previous one, and the diagnostics of its package are published if needed.
+//
+// Documents that are closed without content go back to their content on disk.
+func (s *Server) update(uri string, content *string, publish bool) *responseError {
+	filename, err := uriToPath(uri)
+
+	if err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+
+	dir := filepath.Dir(filename)
+
+	switch {
+	case content != nil:
+		previous, ok := s.overlay[filename]
+
+		// Opening a document that didn't change since it was read from disk
+		// keeps its package.
+		if !ok {
+			if data, err := ioutil.ReadFile(filename); err == nil {
+				previous, ok = data, true
+			}
+		}
+
+		s.overlay[filename] = []byte(*content)
+
+		if !ok || string(previous) != *content {
+			s.invalidate(dir)
+		}
+	case !publish:
+		delete(s.overlay, filename)
+		s.invalidate(dir)
+	}
+
+	if !publish {
+		return nil
+	}
+
+	if err := s.publish(dir); err != nil {
+		s.logError(err.Error())
+	}
+
+	return nil
+}
+
+// invalidate drops the package in a directory, along with the packages that
+// import it, directly or not, as they refer to its previous types. Their
+// diagnostics are only published again when their documents are.
+func (s *Server) invalidate(dir string) {
+	delete(s.packages, dir)
+
+	path, ok := s.paths[dir]
+
+	if !ok {
+		return
+	}
+
+	for importerDir, info := range s.packages {
+		if imports(info.Package, path, map[*types.Package]bool{}) {
+			delete(s.packages, importerDir)
+		}
+	}
+}
+
+// imports checks whether a package imports the package with the specified
+// path, directly or not.
+func imports(pkg *types.Package, path string, seen map[*types.Package]bool) bool {
+	for _, imported := range pkg.Imports() {
+		if imported.Path() == path {
+			return true
+		}
+
+		if !seen[imported] {
+			seen[imported] = true
+
+			if imports(imported, path, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// load type-checks the packages matching a pattern from a directory.
+func (s *Server) load(dir string, pattern string) ([]depbleed.PackageInfo, error) {
+	var options []depbleed.Option
+
+	config := s.Config
+
+	if config == nil {
+		configDir := dir
+
+		if s.root != "" {
+			if rel, err := filepath.Rel(s.root, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				configDir = s.root
+			}
+		}
+
+		var err error
+
+		if config, err = depbleed.FindConfig(configDir); err != nil {
+			return nil, fmt.Errorf("could not find configuration file: %s", err)
+		}
+	}
+
+	if config != nil {
+		options = append(options, depbleed.ConfigOption(*config))
+	}
+
+	options = append(options, s.Options...)
+	options = append(options, depbleed.DirOption(dir), depbleed.OverlayOption(s.overlay))
+
+	infos, err := depbleed.GetPackageInfos([]string{pattern}, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		s.packages[info.Dir] = info
+		s.paths[info.Dir] = info.Package.Path()
+	}
+
+	return infos, nil
+}
+
+// publish publishes the diagnostics of the package in a directory, for all its
+// files, so that the fixed leaks are cleared.
+func (s *Server) publish(dir string) error {
+	info, ok := s.packages[dir]
+
+	if !ok {
+		if _, err := s.load(dir, "."); err != nil {
+			return err
+		}
+
+		if info, ok = s.packages[dir]; !ok {
+			return fmt.Errorf("no package in \"%s\"", dir)
+		}
+	}
+
+	diagnostics := map[string][]diagnostic{}
+
+	for _, file := range info.Files {
+		diagnostics[info.Fset.Position(file.Pos()).Filename] = []diagnostic{}
+	}
+
+	for _, leak := range info.Leaks() {
+		diagnostics[leak.Position.Filename] = append(diagnostics[leak.Position.Filename], diagnostic{
+			Range:    getRange(leak.Position.Line, leak.Position.Column, len(leak.Object.Name())),
+			Severity: getSeverity(leak.Severity),
+			Code:     leak.Kind().String(),
+			Source:   "depbleed",
+			Message:  leak.Error(),
+		})
+	}
+
+	for _, suppression := range info.UnusedSuppressions() {
+		diagnostics[suppression.Position.Filename] = append(diagnostics[suppression.Position.Filename], diagnostic{
+			Range:    getRange(suppression.Position.Line, suppression.Position.Column, len(suppressionDirective)),
+			Severity: severityWarning,
+			Source:   "depbleed",
+			Message:  fmt.Sprintf("unused %s directive", suppressionDirective),
+		})
+	}
+
+	var filenames []string
+
+	for filename := range diagnostics {
+		filenames = append(filenames, filename)
+	}
+
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		if err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         pathToURI(filename),
+			Diagnostics: diagnostics[filename],
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getRange returns the range of the specified length at a position with
+// 1-based line and column.
+//
+// Columns are counted in bytes rather than UTF-16 code units, which only
+// differ on lines with non-ASCII characters.
+func getRange(line int, column int, length int) textRange {
+	start := position{Line: line - 1, Character: column - 1}
+
+	return textRange{
+		Start: start,
+		End:   position{Line: start.Line, Character: start.Character + length},
+	}
+}
+
+// getSeverity returns the diagnostic severity of a leak severity level.
+func getSeverity(severity depbleed.Severity) int {
+	switch severity {
+	case depbleed.SeverityWarning:
+		return severityWarning
+	case depbleed.SeverityInfo:
+		return severityInformation
+	default:
+		return severityError
+	}
+}
+
+// reply sends the response to a request.
+func (s *Server) reply(id *json.RawMessage, result interface{}, responseErr *responseError) error {
+	resp := response{JSONRPC: "2.0", ID: id, Error: responseErr}
+
+	if responseErr == nil {
+		data, err := json.Marshal(result)
+
+		if err != nil {
+			return fmt.Errorf("cannot marshal result: %s", err)
+		}
+
+		raw := json.RawMessage(data)
+		resp.Result = &raw
+	}
+
+	if err := s.queue.send(resp); err != nil {
+		return fmt.Errorf("cannot write response: %s", err)
+	}
+
+	return nil
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params interface{}) error {
+	if err := s.queue.send(notification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		return fmt.Errorf("cannot write notification: %s", err)
+	}
+
+	return nil
+}
+
+// logError shows an error in the client log.
+func (s *Server) logError(message string) {
+	s.notify("window/logMessage", logMessageParams{Type: messageTypeError, Message: message})
+}
+
+// messageQueue writes messages on a writer in the order they are sent, from
+// its own goroutine: clients may not read the messages of the server while
+// they write theirs, so sending never blocks.
+type messageQueue struct {
+	w        io.Writer
+	mu       sync.Mutex
+	cond     *sync.Cond
+	messages []interface{}
+	closed   bool
+	err      error
+	done     chan struct{}
+}
+
+func newMessageQueue(w io.Writer) *messageQueue {
+	q := &messageQueue{w: w, done: make(chan struct{})}
+	q.cond = sync.NewCond(&q.mu)
+
+	go q.run()
+
+	return q
+}
+
+// run writes the messages until the queue is closed and empty, or a write
+// fails.
+func (q *messageQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+
+		for len(q.messages) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+
+		if len(q.messages) == 0 {
+			q.mu.Unlock()
+
+			return
+		}
+
+		message := q.messages[0]
+		q.messages = q.messages[1:]
+		q.mu.Unlock()
+
+		if err := writeMessage(q.w, message); err != nil {
+			q.mu.Lock()
+			q.err = err
+			q.messages = nil
+			q.mu.Unlock()
+
+			return
+		}
+	}
+}
+
+// send queues a message. It returns the error of a previous write, if any.
+func (q *messageQueue) send(message interface{}) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.err != nil {
+		return q.err
+	}
+
+	q.messages = append(q.messages, message)
+	q.cond.Signal()
+
+	return nil
+}
+
+// close waits for the queued messages to be written.
+func (q *messageQueue) close() error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Signal()
+	q.mu.Unlock()
+
+	<-q.done
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.err
+}
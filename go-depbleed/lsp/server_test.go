@@ -0,0 +1,277 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// client is an in-process client of a server.
+type client struct {
+	t      *testing.T
+	w      io.Writer
+	r      *bufio.Reader
+	nextID int
+}
+
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+func (c *client) notify(method string, params interface{}) {
+	if err := writeMessage(c.w, notification{JSONRPC: "2.0", Method: method, Params: params}); err != nil {
+		c.t.Fatalf("expected no error but got: %s", err)
+	}
+}
+
+func (c *client) call(method string, params interface{}) message {
+	c.nextID++
+
+	if err := writeMessage(c.w, map[string]interface{}{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params}); err != nil {
+		c.t.Fatalf("expected no error but got: %s", err)
+	}
+
+	for {
+		msg := c.receive()
+
+		if msg.ID != nil && *msg.ID == c.nextID {
+			return msg
+		}
+	}
+}
+
+// read reads the next message.
+func (c *client) read() message {
+	data, err := readMessage(c.r)
+
+	if err != nil {
+		c.t.Fatalf("expected no error but got: %s", err)
+	}
+
+	var msg message
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.t.Fatalf("expected no error but got: %s", err)
+	}
+
+	return msg
+}
+
+// receive reads the next message, which must not be a log message.
+func (c *client) receive() message {
+	msg := c.read()
+
+	if msg.Method == "window/logMessage" {
+		c.t.Fatalf("unexpected log message: %s", msg.Params)
+	}
+
+	return msg
+}
+
+// logMessage returns the next message logged by the server.
+func (c *client) logMessage() string {
+	for {
+		msg := c.read()
+
+		if msg.Method != "window/logMessage" {
+			continue
+		}
+
+		var params logMessageParams
+
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			c.t.Fatalf("expected no error but got: %s", err)
+		}
+
+		return params.Message
+	}
+}
+
+// diagnostics returns the next diagnostics published for a document.
+func (c *client) diagnostics(uri string) []diagnostic {
+	for {
+		msg := c.receive()
+
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+
+		var params publishDiagnosticsParams
+
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			c.t.Fatalf("expected no error but got: %s", err)
+		}
+
+		if params.URI == uri {
+			return params.Diagnostics
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	root, err := filepath.Abs("../../examples/exwrap")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	filename := filepath.Join(root, "lib.go")
+	content, err := os.ReadFile(filename)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	server := &Server{}
+	done := make(chan error, 1)
+
+	go func() {
+		done <- server.Serve(serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+
+	c := &client{t: t, w: clientWriter, r: bufio.NewReader(clientReader)}
+
+	msg := c.call("initialize", map[string]interface{}{"rootUri": pathToURI(root)})
+
+	var result initializeResult
+
+	if err := json.Unmarshal(msg.Result, &result); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if sync := result.Capabilities.TextDocumentSync; !sync.OpenClose || sync.Change != textDocumentSyncFull || !sync.Save {
+		t.Errorf("unexpected text document sync: %+v", sync)
+	}
+
+	c.notify("initialized", struct{}{})
+
+	// Requests are handled in order: the workspace is loaded once the request
+	// is answered.
+	c.call("unknown", nil)
+
+	warm, ok := server.packages[root]
+
+	if !ok {
+		t.Fatalf("expected the package of %s to be loaded", root)
+	}
+
+	uri := pathToURI(filename)
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "go", "version": 1, "text": string(content)},
+	})
+
+	diagnostics := c.diagnostics(uri)
+
+	// The document didn't change since it was loaded.
+	if server.packages[root].Package != warm.Package {
+		t.Error("expected the loaded package to be kept")
+	}
+
+	if len(diagnostics) != 4 {
+		t.Fatalf("expected 4 diagnostics but got: %v", diagnostics)
+	}
+
+	expected := diagnostic{
+		Range:    textRange{Start: position{Line: 10, Character: 5}, End: position{Line: 10, Character: 14}},
+		Severity: severityError,
+		Code:     "vendored",
+		Source:   "depbleed",
+		Message:  "NewClient: function result 0 is an external type: pointer to external type: a.Client is a vendorized type from github.com/depbleed/go/examples/exwrap/vendor/a",
+	}
+
+	if diagnostics[1] != expected {
+		t.Errorf("expected \"%+v\" but got \"%+v\"", expected, diagnostics[1])
+	}
+
+	// Unsaved changes are only analyzed on save.
+	changed := strings.Replace(string(content), "func DefaultConfig() a.Config {\n\treturn a.Config{Name: \"default\"}", "func DefaultConfig() string {\n\treturn \"default\"", 1)
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": changed}},
+	})
+	c.notify("textDocument/didSave", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	})
+
+	if diagnostics := c.diagnostics(uri); len(diagnostics) != 3 {
+		t.Errorf("expected 3 diagnostics but got: %v", diagnostics)
+	}
+
+	if msg := c.call("unknown", nil); msg.Error == nil || msg.Error.Code != methodNotFound {
+		t.Errorf("expected a method not found error but got: %+v", msg.Error)
+	}
+
+	if msg := c.call("shutdown", nil); msg.Error != nil || string(msg.Result) != "null" {
+		t.Errorf("expected a null result but got: %s (%+v)", msg.Result, msg.Error)
+	}
+
+	c.notify("exit", nil)
+
+	if err := <-done; err != nil {
+		t.Errorf("expected no error but got: %s", err)
+	}
+}
+
+func TestServerLoadError(t *testing.T) {
+	root, err := ioutil.TempDir("", "depbleed")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	defer os.RemoveAll(root)
+
+	filename := filepath.Join(root, "broken.go")
+	content := "package broken\n\nvar A = undefined\n"
+
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- (&Server{}).Serve(serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+
+	c := &client{t: t, w: clientWriter, r: bufio.NewReader(clientReader)}
+
+	c.call("initialize", map[string]interface{}{"rootUri": pathToURI(root)})
+
+	// The errors are logged while the client keeps writing.
+	c.notify("initialized", struct{}{})
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": pathToURI(filename), "languageId": "go", "version": 1, "text": content},
+	})
+
+	for _, name := range []string{"initialized", "didOpen"} {
+		if message := c.logMessage(); !strings.Contains(message, "undefined") {
+			t.Errorf("expected the load error to be logged on %s but got: %s", name, message)
+		}
+	}
+
+	if msg := c.call("shutdown", nil); msg.Error != nil {
+		t.Errorf("expected no error but got: %+v", msg.Error)
+	}
+
+	c.notify("exit", nil)
+
+	if err := <-done; err != nil {
+		t.Errorf("expected no error but got: %s", err)
+	}
+}
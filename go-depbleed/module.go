@@ -0,0 +1,107 @@
+package depbleed
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Module represents a Go module.
+type Module struct {
+	// Path is the module path, as declared in the `go.mod` file.
+	Path string
+	// Dir is the directory that contains the `go.mod` file.
+	Dir string
+}
+
+// FindModule finds the module that contains the specified directory.
+//
+// The directory and all its parents are searched for a `go.mod` file. The
+// directory itself does not need to exist.
+//
+// If no `go.mod` file is found, a nil module is returned with no error.
+func FindModule(dir string) (*Module, error) {
+	dir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine absolute path for \"%s\": %s", dir, err)
+	}
+
+	for {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+
+		if err == nil {
+			modulePath := parseModulePath(data)
+
+			if modulePath == "" {
+				return nil, fmt.Errorf("no module path in \"%s\"", filepath.Join(dir, "go.mod"))
+			}
+
+			return &Module{Path: modulePath, Dir: dir}, nil
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("cannot read \"%s\": %s", filepath.Join(dir, "go.mod"), err)
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+}
+
+// ImportPath returns the import path of the package in the specified
+// directory, relative to the module directory.
+//
+// If the directory is not inside the module, an error is returned.
+func (m Module) ImportPath(dir string) (string, error) {
+	rel, err := filepath.Rel(m.Dir, dir)
+
+	if err != nil {
+		return "", fmt.Errorf("cannot determine if path \"%s\" is in module %s: %s", dir, m.Path, err)
+	}
+
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path \"%s\" is not in module %s", dir, m.Path)
+	}
+
+	return path.Join(m.Path, filepath.ToSlash(rel)), nil
+}
+
+// parseModulePath returns the module path declared in the content of a
+// `go.mod` file, or an empty string if there is none.
+func parseModulePath(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if index := strings.Index(line, "//"); index >= 0 {
+			line = line[:index]
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		if modulePath, err := strconv.Unquote(fields[1]); err == nil {
+			return modulePath
+		}
+
+		return fields[1]
+	}
+
+	return ""
+}
@@ -0,0 +1,130 @@
+package depbleed
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFindModule(t *testing.T) {
+	fixturesModule, _ := filepath.Abs("./fixtures/_module")
+	testCases := []struct {
+		Dir      string
+		Expected *Module
+	}{
+		{
+			Dir:      "./fixtures/_module",
+			Expected: &Module{Path: "example.com/mod", Dir: fixturesModule},
+		},
+		{
+			Dir:      "./fixtures/_module/sub",
+			Expected: &Module{Path: "example.com/mod", Dir: fixturesModule},
+		},
+		{
+			Dir:      "./fixtures/_module/unexisting/...",
+			Expected: &Module{Path: "example.com/mod", Dir: fixturesModule},
+		},
+		{
+			Dir:      "./fixtures/_module/nested",
+			Expected: &Module{Path: "example.com/nested", Dir: filepath.Join(fixturesModule, "nested")},
+		},
+		{
+			Dir:      "./fixtures/gopath/src/foo",
+			Expected: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Dir, func(t *testing.T) {
+			module, err := FindModule(filepath.FromSlash(testCase.Dir))
+
+			if err != nil {
+				t.Fatalf("expected no error but got: %s", err)
+			}
+
+			if testCase.Expected == nil {
+				if module != nil {
+					t.Errorf("expected no module but got: %v", *module)
+				}
+			} else if module == nil {
+				t.Errorf("expected %v but got no module", *testCase.Expected)
+			} else if *module != *testCase.Expected {
+				t.Errorf("expected %v but got %v", *testCase.Expected, *module)
+			}
+		})
+	}
+}
+
+func TestModuleImportPath(t *testing.T) {
+	module := Module{Path: "example.com/mod", Dir: filepath.FromSlash("/tmp/mod")}
+	testCases := []struct {
+		Dir      string
+		Expected string
+	}{
+		{
+			Dir:      "/tmp/mod",
+			Expected: "example.com/mod",
+		},
+		{
+			Dir:      "/tmp/mod/foo/bar",
+			Expected: "example.com/mod/foo/bar",
+		},
+		{
+			Dir:      "/tmp/module",
+			Expected: "",
+		},
+		{
+			Dir:      "/tmp",
+			Expected: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Dir, func(t *testing.T) {
+			value, err := module.ImportPath(filepath.FromSlash(testCase.Dir))
+
+			if testCase.Expected == "" {
+				if err == nil {
+					t.Errorf("expected an error but got: %s", value)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected no error but got: %s", err)
+				}
+
+				if value != testCase.Expected {
+					t.Errorf("expected \"%s\" but got \"%s\"", testCase.Expected, value)
+				}
+			}
+		})
+	}
+}
+
+func TestParseModulePath(t *testing.T) {
+	testCases := []struct {
+		Data     string
+		Expected string
+	}{
+		{
+			Data:     "module example.com/mod\n",
+			Expected: "example.com/mod",
+		},
+		{
+			Data:     "// Comment.\nmodule \"example.com/mod\" // Quoted.\n\ngo 1.11\n",
+			Expected: "example.com/mod",
+		},
+		{
+			Data:     "go 1.11\n",
+			Expected: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Expected, func(t *testing.T) {
+			value := parseModulePath([]byte(testCase.Data))
+
+			if value != testCase.Expected {
+				t.Errorf("expected \"%s\" but got \"%s\"", testCase.Expected, value)
+			}
+		})
+	}
+}
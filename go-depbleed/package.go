@@ -2,109 +2,88 @@ package depbleed
 
 import (
 	"fmt"
+	"go/ast"
 	"go/token"
 	"go/types"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 
-	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/packages"
 )
 
 func isFilePath(path string) bool {
 	return filepath.IsAbs(path) || strings.HasPrefix(path, ".")
 }
 
-func isVendor(path string) bool {
-	return path == "vendor"
-}
-
 func isHidden(path string) bool {
 	return strings.HasPrefix(path, ".")
 }
 
-func goPackagesWalkFunc(gopath string, packages map[string]bool) filepath.WalkFunc {
-	return func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			if isVendor(info.Name()) {
-				return filepath.SkipDir
-			}
-
-			if isHidden(info.Name()) {
-				return filepath.SkipDir
-			}
-		} else if filepath.Ext(path) == ".go" {
-			packagePath, _ := filepath.Rel(filepath.Join(gopath, "src"), filepath.Dir(path))
-			packages[packagePath] = true
-		}
+// ListPackagePaths returns the paths of the packages matching the specified
+// patterns, with the same semantics as `go list`: patterns can be import
+// paths, relative or absolute directories, with `...` wildcards, or `all`.
+//
+// Patterns are resolved from the specified directory, or from the current
+// directory if it is empty. Packages are de-duplicated and sorted. An error
+// is returned if a pattern is invalid, or if no package matches the patterns.
+func ListPackagePaths(dir string, patterns ...string) ([]string, error) {
+	config := &packages.Config{
+		Mode: packages.NeedName,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(config, patterns...)
 
-		return nil
+	if err != nil {
+		return nil, fmt.Errorf("cannot list packages: %s", err)
 	}
-}
 
-func scanGoPackages(gopath string, path string) (result []string, err error) {
-	packages := make(map[string]bool)
-	err = filepath.Walk(path, goPackagesWalkFunc(gopath, packages))
+	seen := map[string]bool{}
+	result := []string{}
 
-	if err != nil {
-		return nil, fmt.Errorf("unable to walk through \"%s\": %s", path, err)
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("cannot list package \"%s\": %s", pkg.ID, pkg.Errors[0])
+		}
+
+		if !seen[pkg.PkgPath] {
+			seen[pkg.PkgPath] = true
+			result = append(result, pkg.PkgPath)
+		}
 	}
 
-	for path := range packages {
-		result = append(result, path)
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no packages match %s", strings.Join(patterns, " "))
 	}
 
 	sort.Strings(result)
 
-	return
+	return result, nil
 }
 
 // GetPackagePaths returns the package paths for the packages matching the
 // specified `path`.
 //
 // If `path` is a Go package path, is it returned as-is. This is a convenience.
+// Otherwise, the packages are listed with ListPackagePaths from the `path`
+// directory, so that they are resolved within its module, if any. If `path`
+// ends with ..., subpackages are also listed.
 //
-// If `path` is either an absolute file path, or starts with a dot, the
-// specified `gopath` is used to determine the package paths.
+// The `gopath` is ignored: the go tool uses the GOPATH of the environment.
 //
-// If the file `path` is not in `gopath` or if it's relative position to the
-// `gopath` can't be determined, an error is returned.
-//
-// If `path` is a filepath and ends with ..., subpackages are also looked for
-// recursively.
-//
-// GetPackagePaths does not check for the package existence and will hapilly
-// return a package path for a non-existing package.
+// Deprecated: use ListPackagePaths, which follows the semantics of the go
+// tool. GetPackagePaths will be removed in a future release.
 func GetPackagePaths(gopath string, path string) ([]string, error) {
-	if isFilePath(path) {
-		path, _ = filepath.Abs(path)
-		packagePath, err := filepath.Rel(filepath.Join(gopath, "src"), path)
-
-		if err != nil {
-			return nil, fmt.Errorf("cannot determine if path \"%s\" is in GOPATH (%s): %s", path, gopath, err)
-		}
-
-		if strings.HasPrefix(packagePath, "..") {
-			return nil, fmt.Errorf("path \"%s\" is not in GOPATH (%s)", path, gopath)
-		}
-
-		if strings.HasSuffix(packagePath, "...") {
-			dir := filepath.Dir(path)
-
-			return scanGoPackages(gopath, dir)
-		}
+	if !isFilePath(path) {
+		return []string{path}, nil
+	}
 
-		return []string{filepath.ToSlash(packagePath)}, nil
+	if strings.HasSuffix(path, "...") {
+		return ListPackagePaths(strings.TrimSuffix(path, "..."), "./...")
 	}
 
-	return []string{path}, nil
+	return ListPackagePaths(path, ".")
 }
 
 // PackageInfo represents information about a package.
@@ -112,7 +91,35 @@ type PackageInfo struct {
 	Package *types.Package
 	Info    types.Info
 	Fset    *token.FileSet
+	// Files are the syntax trees of the package source files.
+	Files []*ast.File
+	// Overlay are the contents the package was loaded with instead of the
+	// files on disk, by absolute filename.
+	Overlay map[string][]byte
 	VCSRoot string
+	// Dir is the directory that contains the package source files.
+	Dir string
+	// Module is the module that contains the package, if any.
+	Module *Module
+	// Roots are the package roots. When empty, GetRoot is used instead.
+	Roots []string
+	// StandardPackages is the sorted list of standard packages. When nil, the
+	// standard packages of the Go toolchain in use are used.
+	StandardPackages []string
+	// AllowedPackages are package patterns whose types are treated as
+	// standard types.
+	AllowedPackages []PackagePattern
+	// Excluded indicates that the package is excluded from the analysis.
+	Excluded bool
+	// Severities are the severity levels of the leak kinds. Leak kinds that
+	// are not listed are errors.
+	Severities map[LeakKind]Severity
+	// ForTest is the path of the package whose tests the package is built
+	// for, for the test variants of packages and external test packages.
+	ForTest string
+	// Configuration is the build configuration the package was type-checked
+	// for, if one was specified.
+	Configuration *BuildConfiguration
 }
 
 // Option represents an option for PackageInfo.
@@ -120,6 +127,95 @@ type Option interface {
 	apply(i *PackageInfo) error
 }
 
+// loaderOption is implemented by options that affect how packages are
+// loaded.
+type loaderOption interface {
+	configure(config *packages.Config)
+}
+
+type dirOption struct {
+	dir string
+}
+
+type allowPackagesOption struct {
+	patterns []string
+}
+
+// AllowPackagesOption returns an option that treats the types from the
+// packages matching the specified patterns like standard types.
+//
+// See MatchPackagePattern for the pattern syntax.
+func AllowPackagesOption(patterns ...string) Option {
+	return allowPackagesOption{patterns: patterns}
+}
+
+func (o allowPackagesOption) apply(i *PackageInfo) error {
+	for _, pattern := range o.patterns {
+		i.AllowedPackages = append(i.AllowedPackages, CompilePackagePattern(pattern))
+	}
+
+	return nil
+}
+
+// DirOption returns an option that loads packages from the specified
+// directory.
+//
+// In module mode, package paths are resolved within the module that contains
+// that directory. By default, the current working directory is used.
+func DirOption(dir string) Option {
+	return dirOption{dir: dir}
+}
+
+func (o dirOption) configure(config *packages.Config) {
+	config.Dir = o.dir
+}
+
+func (dirOption) apply(*PackageInfo) error {
+	return nil
+}
+
+type overlayOption struct {
+	overlay map[string][]byte
+}
+
+// OverlayOption returns an option that loads the specified contents instead
+// of the files on disk, by absolute filename, like the unsaved buffers of an
+// editor.
+func OverlayOption(overlay map[string][]byte) Option {
+	return overlayOption{overlay: overlay}
+}
+
+func (o overlayOption) configure(config *packages.Config) {
+	config.Overlay = o.overlay
+}
+
+func (o overlayOption) apply(i *PackageInfo) error {
+	i.Overlay = o.overlay
+
+	return nil
+}
+
+type includeTestsOption struct{}
+
+// IncludeTestsOption returns an option that also loads the test files of the
+// packages, and their external test packages.
+//
+// Each package then has a test variant, that includes its test files, in
+// addition to its plain variant. Only the leaks declared in test files are
+// reported for the test variant, as the others are reported for the plain
+// variant already.
+func IncludeTestsOption() Option {
+	return includeTestsOption{}
+}
+
+func (includeTestsOption) configure(config *packages.Config) {
+	config.Tests = true
+}
+
+func (includeTestsOption) apply(*PackageInfo) error {
+	return nil
+}
+
 type useVCSRootOption struct {
 	gopath string
 }
@@ -130,7 +226,12 @@ func UseVCSRootOption(gopath string) Option {
 }
 
 func (o useVCSRootOption) apply(i *PackageInfo) error {
-	path := filepath.Join(o.gopath, "src", i.Package.Path())
+	path := i.Dir
+
+	if path == "" {
+		path = filepath.Join(filepath.SplitList(o.gopath)[0], "src", i.importPath())
+	}
+
 	cmd := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")
 
 	output, err := cmd.Output()
@@ -141,64 +242,238 @@ func (o useVCSRootOption) apply(i *PackageInfo) error {
 
 	vcsRoot := strings.TrimSpace(string(output))
 
+	if i.Module != nil {
+		return i.applyModuleVCSRoot(vcsRoot)
+	}
+
+	// The GOPATH can list several directories: the VCS root is relative to
+	// the one that contains it.
+	for _, gopath := range filepath.SplitList(o.gopath) {
+		// This is necessary because `git rev-parse` will return resolved symlinks.
+		fullGopath, err := filepath.EvalSymlinks(filepath.Join(gopath, "src"))
+
+		if err != nil {
+			continue
+		}
+
+		if rel, err := filepath.Rel(fullGopath, vcsRoot); err == nil && !strings.HasPrefix(rel, "..") {
+			i.VCSRoot = filepath.ToSlash(rel)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot determine VCS root relative to GOPATH (%s): %s is not in it", o.gopath, vcsRoot)
+}
+
+// applyModuleVCSRoot sets the VCS root of a package that belongs to a module.
+//
+// The VCS root is expressed as a package path, which is only possible when
+// the module path ends with the location of the module in the repository. If
+// it doesn't, or if the module directory is unknown, the module path is used
+// instead.
+func (i *PackageInfo) applyModuleVCSRoot(vcsRoot string) error {
+	if i.Module.Dir == "" {
+		i.VCSRoot = i.Module.Path
+
+		return nil
+	}
+
 	// This is necessary because `git rev-parse` will return resolved symlinks.
-	fullGopath, err := filepath.EvalSymlinks(filepath.Join(o.gopath, "src"))
+	moduleDir, err := filepath.EvalSymlinks(i.Module.Dir)
 
 	if err != nil {
-		return fmt.Errorf("cannot determine absolute GOPATH (%s): %s", o.gopath, err)
+		return fmt.Errorf("cannot determine absolute module directory (%s): %s", i.Module.Dir, err)
 	}
 
-	if vcsRoot, err = filepath.Rel(fullGopath, vcsRoot); err != nil {
-		return fmt.Errorf("cannot determine VCS root relative to GOPATH (%s): %s", o.gopath, err)
+	rel, err := filepath.Rel(vcsRoot, moduleDir)
+
+	if err != nil {
+		return fmt.Errorf("cannot determine module directory relative to VCS root (%s): %s", vcsRoot, err)
 	}
 
-	i.VCSRoot = vcsRoot
+	rel = filepath.ToSlash(rel)
+
+	switch {
+	case rel == ".":
+		i.VCSRoot = i.Module.Path
+	case strings.HasSuffix(i.Module.Path, "/"+rel):
+		i.VCSRoot = strings.TrimSuffix(i.Module.Path, "/"+rel)
+	default:
+		i.VCSRoot = i.Module.Path
+	}
 
 	return nil
 }
 
-// GetPackageInfo returns information about the package at the specified
-// location.
-func GetPackageInfo(p string, options ...Option) (PackageInfo, error) {
-	var config loader.Config
-	config.Import(p)
-	var nestedErr error
+// loadMode is the information loaded for every package.
+//
+// Dependencies are not parsed: their types come from export data, like
+// `go vet` does.
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedTypes |
+	packages.NeedTypesSizes |
+	packages.NeedSyntax |
+	packages.NeedTypesInfo |
+	packages.NeedModule |
+	packages.NeedForTest
+
+// GetPackageInfos returns information about the packages matching the
+// specified patterns.
+//
+// All packages are loaded and type-checked at once and share the same file
+// set and type information.
+//
+// In module mode, the module of each package is used as its package root.
+func GetPackageInfos(patterns []string, options ...Option) ([]PackageInfo, error) {
+	config := packages.Config{
+		Mode: loadMode,
+		Fset: token.NewFileSet(),
+	}
 
-	config.TypeChecker.Error = func(err error) {
-		nestedErr = err
+	for _, option := range options {
+		if option, ok := option.(loaderOption); ok {
+			option.configure(&config)
+		}
 	}
 
-	program, err := config.Load()
+	pkgs, err := packages.Load(&config, patterns...)
 
 	if err != nil {
-		return PackageInfo{}, fmt.Errorf("%s: %s", err, nestedErr)
+		return nil, fmt.Errorf("cannot load packages: %s", err)
 	}
 
-	packageInfo := program.Package(p)
-
-	info := PackageInfo{
-		Package: packageInfo.Pkg,
-		Info:    packageInfo.Info,
-		Fset:    config.Fset,
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no packages matching %s", strings.Join(patterns, " "))
 	}
 
-	for _, option := range options {
-		if err := option.apply(&info); err != nil {
-			return PackageInfo{}, err
+	result := make([]PackageInfo, 0, len(pkgs))
+
+	for _, pkg := range pkgs {
+		// Test binaries only contain generated code.
+		if config.Tests && pkg.Name == "main" && strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue
 		}
+
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("cannot load package \"%s\": %s", pkg.PkgPath, pkg.Errors[0])
+		}
+
+		info := PackageInfo{
+			Package: pkg.Types,
+			Info:    *pkg.TypesInfo,
+			Fset:    config.Fset,
+			Files:   pkg.Syntax,
+			ForTest: pkg.ForTest,
+		}
+
+		if len(pkg.GoFiles) > 0 {
+			info.Dir = filepath.Dir(pkg.GoFiles[0])
+		}
+
+		if pkg.Module != nil {
+			info.Module = &Module{
+				Path: pkg.Module.Path,
+				Dir:  pkg.Module.Dir,
+			}
+		}
+
+		for _, option := range options {
+			if err := option.apply(&info); err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, info)
 	}
 
-	return info, nil
+	return result, nil
+}
+
+// GetPackageInfo returns information about the package at the specified
+// location.
+//
+// To get information about several packages, GetPackageInfos is much more
+// efficient.
+func GetPackageInfo(p string, options ...Option) (PackageInfo, error) {
+	infos, err := GetPackageInfos([]string{p}, options...)
+
+	if err != nil {
+		return PackageInfo{}, err
+	}
+
+	return infos[0], nil
 }
 
 // GetRoot gets the root of the package.
+//
+// The VCS root takes precedence, then the module path and finally the package
+// path.
 func (i PackageInfo) GetRoot() string {
-	switch i.VCSRoot {
-	case "":
-		return i.Package.Path()
-	default:
+	switch {
+	case i.VCSRoot != "":
 		return i.VCSRoot
+	case i.Module != nil:
+		return i.Module.Path
+	default:
+		return i.importPath()
+	}
+}
+
+// importPath returns the path the package is imported with: test variants
+// and external test packages are part of the package they test.
+func (i PackageInfo) importPath() string {
+	if i.ForTest != "" {
+		return i.ForTest
+	}
+
+	return i.Package.Path()
+}
+
+// reports checks whether the objects declared at the specified position are
+// reported for the package: test variants only report their test files.
+func (i PackageInfo) reports(pos token.Pos) bool {
+	return !i.IsTestVariant() || strings.HasSuffix(i.Fset.Position(pos).Filename, "_test.go")
+}
+
+// IsTestVariant checks whether the package is the test variant of a package,
+// that includes its test files.
+func (i PackageInfo) IsTestVariant() bool {
+	return i.ForTest != "" && i.ForTest == i.Package.Path()
+}
+
+// GetRoots gets the roots of the package.
+//
+// Unless roots were explicitly set, the only root is the one returned by
+// GetRoot.
+func (i PackageInfo) GetRoots() []string {
+	if len(i.Roots) > 0 {
+		return i.Roots
+	}
+
+	return []string{i.GetRoot()}
+}
+
+// IsAllowedPackage checks whether a package is explicitly allowed.
+//
+// Vendored packages are allowed by the patterns that match either their path
+// or their upstream path, so that `github.com/foo/...` also allows the vendored
+// copies of these packages.
+//
+// Types from allowed packages are never reported as leaks.
+func (i PackageInfo) IsAllowedPackage(p string) bool {
+	upstreamPath := GetUpstreamPackagePath(p)
+
+	for _, pattern := range i.AllowedPackages {
+		if pattern.Match(p) || pattern.Match(upstreamPath) {
+			return true
+		}
 	}
+
+	return false
 }
 
 // IsMain checks whether the package is a main package.
@@ -207,96 +482,517 @@ func (i PackageInfo) IsMain() bool {
 }
 
 // Leaks returns the leaks in the package.
+//
+// Leaks of objects that have a `//depbleed:ignore` directive are not returned.
 func (i PackageInfo) Leaks() (result Leaks) {
-	if i.IsMain() {
+	suppressions := i.GetSuppressions()
+
+	for _, leak := range i.AllLeaks() {
+		if !suppressions.suppress(leak) {
+			result = append(result, leak)
+		}
+	}
+
+	return
+}
+
+// UnusedSuppressions returns the `//depbleed:ignore` directives that don't
+// suppress any leak.
+//
+// The directives of main and excluded packages are never reported, as these
+// packages have no leaks.
+func (i PackageInfo) UnusedSuppressions() (result Suppressions) {
+	if i.IsMain() || i.Excluded {
 		return
 	}
 
+	leaks := i.AllLeaks()
+
+	for _, suppression := range i.GetSuppressions() {
+		if !Suppressions([]Suppression{suppression}).suppress(leaks...) {
+			result = append(result, suppression)
+		}
+	}
+
+	return
+}
+
+// AllLeaks returns the leaks in the package, including the suppressed ones.
+func (i PackageInfo) AllLeaks() (result Leaks) {
+	if i.IsMain() || i.Excluded {
+		return
+	}
+
+	members := i.getUnexportedMembers()
+	i.addLiteralMembers(members)
+
 	for _, obj := range i.Info.Defs {
-		// Only exported types matter.
-		if obj != nil && obj.Exported() {
-			if err := i.CheckLeaks(obj.Type()); err != nil {
-				result = append(result, Leak{
-					Object:   obj,
-					Position: i.Fset.Position(obj.Pos()),
-					err:      err,
-				})
+		// Only the exported API matters: the fields and methods of unexported
+		// types are checked when these types are reached from it, and the
+		// ones of type literals with the objects of these types.
+		if obj == nil || members[obj] || !i.reports(obj.Pos()) {
+			continue
+		}
+
+		if obj.Exported() || isEmbeddedField(obj) {
+			// Type parameters are checked with the type or function that
+			// declares them.
+			if _, ok := obj.Type().(*types.TypeParam); ok {
+				continue
 			}
+
+			if path := i.checkLeaks(obj.Type(), map[*types.TypeName]bool{}); path != nil {
+				result = append(result, i.newLeak(obj, *path))
+			}
+
+			result = append(result, i.getPromotedLeaks(obj)...)
 		}
 	}
 
 	sort.Sort(result)
+	i.addExtractInterfaceFixes(result)
 
 	return
 }
 
-// CheckLeaks checks wheter a specified type is being leaked.
-func (i PackageInfo) CheckLeaks(t types.Type) error {
-	switch t := t.(type) {
-	case *types.Signature:
-		vars := t.Params()
+// newLeak returns the leak of an object through the specified path.
+func (i PackageInfo) newLeak(obj types.Object, path LeakPath) Leak {
+	leak := Leak{
+		Object:   obj,
+		Position: i.Fset.Position(obj.Pos()),
+		Path:     path,
+		Severity: i.GetSeverity(path.Kind()),
+	}
+
+	if i.Configuration != nil {
+		leak.Configurations = []BuildConfiguration{*i.Configuration}
+	}
+
+	return leak
+}
+
+// getPromotedLeaks returns the leaks caused by the methods promoted to an
+// exported named type from its embedded fields, or from the types embedded in
+// it for interfaces.
+//
+// Promoted methods are not declared in the package, so the leaks are
+// attributed to the embedding fields.
+func (i PackageInfo) getPromotedLeaks(obj types.Object) (result Leaks) {
+	if _, ok := obj.(*types.TypeName); !ok || obj.Parent() != i.Package.Scope() {
+		return
+	}
 
-		nameOrIndex := func(t *types.Tuple, index int) string {
-			name := t.At(index).Name()
+	named, ok := obj.Type().(*types.Named)
 
-			if name == "" {
-				return strconv.Itoa(index)
+	if !ok {
+		return
+	}
+
+	if iface, ok := named.Underlying().(*types.Interface); ok {
+		return i.getEmbeddedInterfaceLeaks(obj, named, iface)
+	}
+
+	s, ok := named.Underlying().(*types.Struct)
+
+	if !ok {
+		return
+	}
+
+	methods := map[types.Object]bool{}
+
+	for _, t := range []types.Type{named, types.NewPointer(named)} {
+		mset := types.NewMethodSet(t)
+
+		for j := 0; j < mset.Len(); j++ {
+			selection := mset.At(j)
+			method := selection.Obj()
+
+			// Methods declared on the type itself are checked on their own.
+			if len(selection.Index()) < 2 || !method.Exported() || methods[method] {
+				continue
+			}
+
+			methods[method] = true
+
+			if path := i.checkLeaks(method.Type(), map[*types.TypeName]bool{}); path != nil {
+				field := s.Field(selection.Index()[0])
+
+				result = append(result, i.newLeak(field, *path.prepend(Step{Kind: PromotedMethodStep, Type: named, Name: method.Name()})))
+			}
+		}
+	}
+
+	return
+}
+
+// getEmbeddedInterfaceLeaks returns the leaks caused by the types embedded in
+// an exported named interface, and by the methods they promote to it.
+//
+// Interfaces have no fields, so the leaks are attributed to the interface.
+func (i PackageInfo) getEmbeddedInterfaceLeaks(obj types.Object, named *types.Named, iface *types.Interface) (result Leaks) {
+	for j := 0; j < iface.NumEmbeddeds(); j++ {
+		embedded := iface.EmbeddedType(j)
+
+		if path := i.checkLeaks(embedded, map[*types.TypeName]bool{}); path != nil {
+			result = append(result, i.newLeak(obj, *path.prepend(Step{Kind: EmbeddedTypeStep, Type: iface, Index: j, Name: GetTypeShortName(embedded)})))
+		}
+	}
+
+	explicit := map[*types.Func]bool{}
+
+	for j := 0; j < iface.NumExplicitMethods(); j++ {
+		explicit[iface.ExplicitMethod(j)] = true
+	}
+
+	for j := 0; j < iface.NumMethods(); j++ {
+		method := iface.Method(j)
+
+		// Methods declared on the interface itself are checked on their own.
+		if explicit[method] || !method.Exported() {
+			continue
+		}
+
+		if path := i.checkLeaks(method.Type(), map[*types.TypeName]bool{}); path != nil {
+			result = append(result, i.newLeak(obj, *path.prepend(Step{Kind: PromotedMethodStep, Type: named, Name: method.Name()})))
+		}
+	}
+
+	return
+}
+
+// isEmbeddedField checks whether the specified object is an embedded field.
+func isEmbeddedField(obj types.Object) bool {
+	field, ok := obj.(*types.Var)
+
+	return ok && field.Embedded()
+}
+
+// getUnexportedMembers returns the fields and methods declared by the
+// unexported types of the package.
+func (i PackageInfo) getUnexportedMembers() map[types.Object]bool {
+	members := map[types.Object]bool{}
+
+	for _, obj := range i.Info.Defs {
+		if _, ok := obj.(*types.TypeName); !ok {
+			continue
+		}
+
+		named, ok := obj.Type().(*types.Named)
+
+		if !ok || !i.isUnexportedType(named) {
+			continue
+		}
+
+		for j := 0; j < named.NumMethods(); j++ {
+			members[named.Method(j)] = true
+		}
+
+		addMembers(named.Underlying(), members)
+	}
+
+	return members
+}
+
+// addLiteralMembers adds the fields and methods declared by the type literals
+// of the package, rather than by named types.
+//
+// Leaks through them are already reported for the variables, functions or
+// fields whose type is the literal.
+func (i PackageInfo) addLiteralMembers(members map[types.Object]bool) {
+	named := map[types.Object]bool{}
+
+	for _, obj := range i.Info.Defs {
+		if _, ok := obj.(*types.TypeName); !ok {
+			continue
+		}
+
+		if t, ok := obj.Type().(*types.Named); ok {
+			switch u := t.Underlying().(type) {
+			case *types.Struct:
+				for j := 0; j < u.NumFields(); j++ {
+					named[u.Field(j)] = true
+				}
+			case *types.Interface:
+				for j := 0; j < u.NumExplicitMethods(); j++ {
+					named[u.ExplicitMethod(j)] = true
+				}
 			}
+		}
+	}
 
-			return fmt.Sprintf("\"%s\"", name)
+	for _, obj := range i.Info.Defs {
+		switch obj := obj.(type) {
+		case *types.Var:
+			if obj.IsField() && !named[obj] {
+				members[obj] = true
+			}
+		case *types.Func:
+			// Methods declared on named types have a named receiver.
+			if recv := obj.Type().(*types.Signature).Recv(); recv != nil && !named[obj] {
+				if _, ok := types.Unalias(recv.Type()).(*types.Interface); ok {
+					members[obj] = true
+				}
+			}
+		}
+	}
+}
+
+// addMembers adds the fields and methods declared by the specified type
+// literal, including the ones of nested type literals.
+func addMembers(t types.Type, members map[types.Object]bool) {
+	switch t := t.(type) {
+	case *types.Pointer:
+		addMembers(t.Elem(), members)
+	case *types.Struct:
+		for j := 0; j < t.NumFields(); j++ {
+			members[t.Field(j)] = true
+			addMembers(t.Field(j).Type(), members)
 		}
+	case *types.Interface:
+		for j := 0; j < t.NumExplicitMethods(); j++ {
+			members[t.ExplicitMethod(j)] = true
+		}
+	}
+}
+
+// CheckLeaks checks wheter a specified type is being leaked.
+//
+// The returned error, if any, is a *LeakPath.
+func (i PackageInfo) CheckLeaks(t types.Type) error {
+	if path := i.checkLeaks(t, map[*types.TypeName]bool{}); path != nil {
+		return path
+	}
+
+	return nil
+}
+
+// checkLeaks implements CheckLeaks, keeping track of the unexported types
+// already visited so that recursive types are walked only once.
+func (i PackageInfo) checkLeaks(t types.Type, visited map[*types.TypeName]bool) *LeakPath {
+	switch t := types.Unalias(t).(type) {
+	case *types.Signature:
+		if path := i.checkTypeParamsLeaks(t.TypeParams(), visited); path != nil {
+			return path
+		}
+
+		vars := t.Params()
 
 		for j := 0; j < vars.Len(); j++ {
-			if err := i.CheckLeaks(vars.At(j).Type()); err != nil {
-				return fmt.Errorf("function argument %s is an external type: %s", nameOrIndex(vars, j), err)
+			if path := i.checkLeaks(vars.At(j).Type(), visited); path != nil {
+				return path.prepend(Step{Kind: ParamStep, Type: t, Index: j, Name: vars.At(j).Name()})
 			}
 		}
 
 		vars = t.Results()
 
 		for j := 0; j < vars.Len(); j++ {
-			if err := i.CheckLeaks(vars.At(j).Type()); err != nil {
-				return fmt.Errorf("function result %s is an external type: %s", nameOrIndex(vars, j), err)
+			if path := i.checkLeaks(vars.At(j).Type(), visited); path != nil {
+				return path.prepend(Step{Kind: ResultStep, Type: t, Index: j, Name: vars.At(j).Name()})
 			}
 		}
 
 		return nil
 	case *types.Chan:
-		if err := i.CheckLeaks(t.Elem()); err != nil {
-			return fmt.Errorf("channel of external type: %s", err)
+		if path := i.checkLeaks(t.Elem(), visited); path != nil {
+			return path.prepend(Step{Kind: ElemStep, Type: t})
 		}
 
 		return nil
 	case *types.Pointer:
-		if err := i.CheckLeaks(t.Elem()); err != nil {
-			return fmt.Errorf("pointer to external type: %s", err)
+		if path := i.checkLeaks(t.Elem(), visited); path != nil {
+			return path.prepend(Step{Kind: ElemStep, Type: t})
 		}
 
 		return nil
 	case *types.Array:
-		if err := i.CheckLeaks(t.Elem()); err != nil {
-			return fmt.Errorf("array item is an external type: %s", err)
+		if path := i.checkLeaks(t.Elem(), visited); path != nil {
+			return path.prepend(Step{Kind: ElemStep, Type: t})
 		}
 
 		return nil
 	case *types.Slice:
-		if err := i.CheckLeaks(t.Elem()); err != nil {
-			return fmt.Errorf("slice item is an external type: %s", err)
+		if path := i.checkLeaks(t.Elem(), visited); path != nil {
+			return path.prepend(Step{Kind: ElemStep, Type: t})
 		}
 
 		return nil
 	case *types.Map:
-		if err := i.CheckLeaks(t.Key()); err != nil {
-			return fmt.Errorf("map key is an external type: %s", err)
+		if path := i.checkLeaks(t.Key(), visited); path != nil {
+			return path.prepend(Step{Kind: MapKeyStep, Type: t})
 		}
 
-		if err := i.CheckLeaks(t.Elem()); err != nil {
-			return fmt.Errorf("map value is an external type: %s", err)
+		if path := i.checkLeaks(t.Elem(), visited); path != nil {
+			return path.prepend(Step{Kind: ElemStep, Type: t})
+		}
+
+		return nil
+	case *types.Named:
+		if path := i.checkPackageLeaks(t); path != nil {
+			return path
+		}
+
+		args := t.TypeArgs()
+
+		for j := 0; j < args.Len(); j++ {
+			if path := i.checkLeaks(args.At(j), visited); path != nil {
+				return path.prepend(Step{Kind: TypeArgumentStep, Type: t, Index: j})
+			}
+		}
+
+		// Only the generic type declaration itself has type parameters without
+		// type arguments.
+		if args.Len() == 0 {
+			if path := i.checkTypeParamsLeaks(t.TypeParams(), visited); path != nil {
+				return path
+			}
+		}
+
+		return i.checkUnexportedLeaks(t, visited)
+	case *types.TypeParam:
+		constraint := types.Unalias(t.Constraint())
+
+		// Constraint literals, like `~int | a.Int`, are implicit interfaces.
+		if iface, ok := constraint.(*types.Interface); ok && iface.IsImplicit() {
+			for j := 0; j < iface.NumEmbeddeds(); j++ {
+				if path := i.checkLeaks(iface.EmbeddedType(j), visited); path != nil {
+					return path.prepend(Step{Kind: ConstraintStep, Type: t, Name: t.Obj().Name()})
+				}
+			}
+
+			return nil
+		}
+
+		if path := i.checkLeaks(constraint, visited); path != nil {
+			return path.prepend(Step{Kind: ConstraintStep, Type: t, Name: t.Obj().Name()})
+		}
+
+		return nil
+	case *types.Struct:
+		return i.checkFieldsLeaks(t, t, visited)
+	case *types.Interface:
+		for j := 0; j < t.NumExplicitMethods(); j++ {
+			method := t.ExplicitMethod(j)
+
+			// Unexported methods can't be called from other packages.
+			if !method.Exported() {
+				continue
+			}
+
+			if path := i.checkLeaks(method.Type(), visited); path != nil {
+				return path.prepend(Step{Kind: MethodStep, Type: t, Name: method.Name()})
+			}
+		}
+
+		for j := 0; j < t.NumEmbeddeds(); j++ {
+			embedded := t.EmbeddedType(j)
+
+			if path := i.checkLeaks(embedded, visited); path != nil {
+				return path.prepend(Step{Kind: EmbeddedTypeStep, Type: t, Index: j, Name: GetTypeShortName(embedded)})
+			}
+		}
+
+		return nil
+	case *types.Union:
+		for j := 0; j < t.Len(); j++ {
+			if path := i.checkLeaks(t.Term(j).Type(), visited); path != nil {
+				return path.prepend(Step{Kind: UnionTermStep, Type: t, Index: j})
+			}
 		}
 
 		return nil
 	}
 
+	return i.checkPackageLeaks(t)
+}
+
+// checkFieldsLeaks checks whether the fields of a struct are being leaked.
+//
+// The steps go from the specified type, which is either the struct itself or
+// the unexported type it is the underlying type of.
+func (i PackageInfo) checkFieldsLeaks(t types.Type, s *types.Struct, visited map[*types.TypeName]bool) *LeakPath {
+	for j := 0; j < s.NumFields(); j++ {
+		field := s.Field(j)
+
+		// Unexported fields can't be accessed from other packages, unless
+		// they are embedded and their own fields and methods get promoted.
+		if !field.Exported() && !field.Embedded() {
+			continue
+		}
+
+		if path := i.checkLeaks(field.Type(), visited); path != nil {
+			if field.Embedded() {
+				return path.prepend(Step{Kind: EmbeddedFieldStep, Type: t, Index: j, Name: field.Name()})
+			}
+
+			return path.prepend(Step{Kind: FieldStep, Type: t, Index: j, Name: field.Name()})
+		}
+	}
+
+	return nil
+}
+
+// isUnexportedType checks whether the specified named type belongs to the
+// package but cannot be named from other packages.
+func (i PackageInfo) isUnexportedType(t *types.Named) bool {
+	obj := t.Obj()
+
+	if obj.Pkg() != i.Package {
+		return false
+	}
+
+	return !obj.Exported() || obj.Parent() != i.Package.Scope()
+}
+
+// checkUnexportedLeaks checks whether an unexported type of the package leaks
+// external types through its exported fields and methods.
+//
+// Such a type is only checked when it is reached from the exported API, as
+// other packages can still use its exported fields and methods.
+func (i PackageInfo) checkUnexportedLeaks(t *types.Named, visited map[*types.TypeName]bool) *LeakPath {
+	if !i.isUnexportedType(t) || visited[t.Obj()] {
+		return nil
+	}
+
+	visited[t.Obj()] = true
+
+	if s, ok := t.Underlying().(*types.Struct); ok {
+		if path := i.checkFieldsLeaks(t, s, visited); path != nil {
+			return path
+		}
+	} else if path := i.checkLeaks(t.Underlying(), visited); path != nil {
+		return path.prepend(Step{Kind: UnderlyingStep, Type: t})
+	}
+
+	for j := 0; j < t.NumMethods(); j++ {
+		method := t.Method(j)
+
+		if !method.Exported() {
+			continue
+		}
+
+		if path := i.checkLeaks(method.Type(), visited); path != nil {
+			return path.prepend(Step{Kind: MethodStep, Type: t, Index: j, Name: method.Name()})
+		}
+	}
+
+	return nil
+}
+
+// checkTypeParamsLeaks checks whether the constraints of the specified type
+// parameters are being leaked.
+func (i PackageInfo) checkTypeParamsLeaks(params *types.TypeParamList, visited map[*types.TypeName]bool) *LeakPath {
+	for j := 0; j < params.Len(); j++ {
+		if path := i.checkLeaks(params.At(j), visited); path != nil {
+			return path
+		}
+	}
+
+	return nil
+}
+
+// checkPackageLeaks checks whether the package of a specified type makes it
+// leak.
+func (i PackageInfo) checkPackageLeaks(t types.Type) *LeakPath {
 	pkgPath := GetTypePackagePath(t)
 
 	// Built-in type.
@@ -305,27 +1001,58 @@ func (i PackageInfo) CheckLeaks(t types.Type) error {
 	}
 
 	// Standard type.
-	if IsStandardPackage(pkgPath) {
+	if i.IsStandardPackage(pkgPath) {
 		return nil
 	}
 
-	// Subpackages are ok.
-	if IsSubPackage(pkgPath, i.GetRoot()) {
+	// Allowed packages are treated like standard packages.
+	if i.IsAllowedPackage(pkgPath) {
 		return nil
 	}
 
-	// Vendors are definitely leaking.
-	if IsVendorPackage(pkgPath, i.Package.Path()) {
-		return fmt.Errorf("%s is a vendorized type from %s", GetTypeShortName(t), pkgPath)
+	// Internal packages can't be imported by all the importers of the package,
+	// even when they are subpackages.
+	if !IsImportablePackage(pkgPath, i.importPath()) {
+		return &LeakPath{
+			Type:        t,
+			PackagePath: pkgPath,
+			Vendored:    IsVendorPackage(pkgPath, i.importPath()),
+			Internal:    true,
+		}
+	}
+
+	// Subpackages are ok.
+	for _, root := range i.GetRoots() {
+		if IsSubPackage(pkgPath, root) {
+			return nil
+		}
 	}
 
-	return fmt.Errorf("%s is a global type from %s", GetTypeShortName(t), pkgPath)
+	return &LeakPath{
+		Type:        t,
+		PackagePath: pkgPath,
+		// Vendors are definitely leaking.
+		Vendored: IsVendorPackage(pkgPath, i.importPath()),
+	}
 }
 
 // GetTypePackagePath returns the package path for a given type.
 //
-// For built-in types (int, string, ...), an empty string is returned.
+// For built-in types (int, string, ...) and type parameters, an empty string
+// is returned.
 func GetTypePackagePath(t types.Type) string {
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		if pkg := t.Obj().Pkg(); pkg != nil {
+			return pkg.Path()
+		}
+
+		// The universe `error` and `comparable` types have no package.
+		return ""
+	case *types.Basic, *types.TypeParam:
+		return ""
+	}
+
 	parts := strings.Split(t.String(), ".")
 
 	if len(parts) == 1 {
@@ -336,23 +1063,13 @@ func GetTypePackagePath(t types.Type) string {
 }
 
 // GetTypeShortName returns the short type representation for a given type.
-func GetTypeShortName(t types.Type) string {
-	parts := strings.Split(t.String(), "/")
-
-	return parts[len(parts)-1]
-}
-
-// IsStandardPackage checks whether a given package is standard.
 //
-// Standard packages are provided with Go.
-func IsStandardPackage(p string) bool {
-	index := sort.SearchStrings(standardGoPackages, p)
-
-	if index < len(standardGoPackages) {
-		return standardGoPackages[index] == p
-	}
-
-	return false
+// Types are qualified by their package name rather than by their package path,
+// including in type arguments.
+func GetTypeShortName(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
 }
 
 // IsVendorPackage checks whether a given package is a vendor of the specified
@@ -363,10 +1080,49 @@ func IsVendorPackage(p string, rootPackage string) bool {
 	return strings.HasPrefix(p, rootPackage) && strings.Contains(p, "/vendor/")
 }
 
+// GetInternalScope returns the package path of the parent of the last
+// `internal` element of a package path.
+//
+// Only packages in the tree rooted at that parent can import the package. If
+// the package path has no `internal` element, `ok` is false.
+func GetInternalScope(p string) (scope string, ok bool) {
+	switch index := strings.LastIndex("/"+p+"/", "/internal/"); index {
+	case -1:
+		return "", false
+	case 0:
+		return "", true
+	default:
+		return p[:index-1], true
+	}
+}
+
+// IsImportablePackage checks whether a given package can be imported by all
+// the packages that can import the specified package.
+//
+// This is not the case when the package is internal and the specified package
+// can be imported by packages outside of its internal scope.
+func IsImportablePackage(p string, importedPackage string) bool {
+	scope, ok := GetInternalScope(p)
+
+	if !ok || scope == "" {
+		return true
+	}
+
+	importedScope, ok := GetInternalScope(importedPackage)
+
+	if !ok {
+		return false
+	}
+
+	return importedScope == scope || strings.HasPrefix(importedScope, scope+"/")
+}
+
 // IsSubPackage checks whether a given package is a subpackage of the specified
 // root package.
 //
 // A package is always a subpackage of itself.
 func IsSubPackage(p string, rootPackage string) bool {
-	return strings.HasPrefix(p, rootPackage) && !strings.Contains(p, "/vendor/")
+	// The root package can itself be vendored: only the vendor directories
+	// below it matter.
+	return strings.HasPrefix(p, rootPackage) && !strings.Contains(strings.TrimPrefix(p, rootPackage), "/vendor/")
 }
@@ -2,64 +2,97 @@ package depbleed
 
 import (
 	"errors"
-	"fmt"
 	"go/token"
 	"go/types"
-	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
-func TestGetPackagePaths(t *testing.T) {
-	fixturesGoPath, _ := filepath.Abs("./fixtures/gopath")
+func TestUseVCSRootOption(t *testing.T) {
+	// Coverage only.
+	option := UseVCSRootOption("my/go/path")
+
+	if option == nil {
+		t.Fatal("expected not nil")
+	}
+}
+
+func TestListPackagePaths(t *testing.T) {
 	testCases := []struct {
-		Gopath   string
-		Path     string
+		Patterns []string
 		Expected []string
 	}{
 		{
-			Gopath:   "/tmp",
-			Path:     "/tmp/src/foo/bar",
-			Expected: []string{"foo/bar"},
+			Patterns: []string{"./..."},
+			Expected: []string{"example.com/patterns/a", "example.com/patterns/b"},
 		},
 		{
-			Gopath:   "./tmp",
-			Path:     "/tmp/foo/bar",
-			Expected: nil,
+			Patterns: []string{"./a", "example.com/patterns/..."},
+			Expected: []string{"example.com/patterns/a", "example.com/patterns/b"},
 		},
 		{
-			Gopath:   "/tmp",
-			Path:     "/tmp/foo/bar",
+			Patterns: []string{"./b"},
+			Expected: []string{"example.com/patterns/b"},
+		},
+		{
+			Patterns: []string{"./unexisting"},
 			Expected: nil,
 		},
 		{
-			Gopath:   "/tmp2",
-			Path:     "/tmp/src/foo/bar",
+			Patterns: []string{"./a/testdata/..."},
 			Expected: nil,
 		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(strings.Join(testCase.Patterns, " "), func(t *testing.T) {
+			values, err := ListPackagePaths("./fixtures/_patterns", testCase.Patterns...)
+
+			if len(testCase.Expected) == 0 {
+				if err == nil {
+					t.Errorf("expected an error but got: %v", values)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected no error but got: %s", err)
+				}
+
+				if !reflect.DeepEqual(values, testCase.Expected) {
+					t.Errorf("expected \"%v\" but got \"%v\"", testCase.Expected, values)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPackagePaths(t *testing.T) {
+	testCases := []struct {
+		Path     string
+		Expected []string
+	}{
 		{
-			Gopath:   "/tmp",
 			Path:     "foo",
 			Expected: []string{"foo"},
 		},
 		{
-			Gopath:   fixturesGoPath,
-			Path:     "./fixtures/gopath/src/foo/...",
-			Expected: []string{"foo", "foo/bar"},
+			Path:     "./fixtures/_module",
+			Expected: []string{"example.com/mod"},
 		},
 		{
-			Gopath:   fixturesGoPath,
-			Path:     "./fixtures/gopath/src/foo/unexisting/...",
+			Path:     "./fixtures/_module/unexisting",
 			Expected: nil,
 		},
+		{
+			Path:     "./fixtures/_patterns/...",
+			Expected: []string{"example.com/patterns/a", "example.com/patterns/b"},
+		},
 	}
 
 	for _, testCase := range testCases {
-		t.Run(fmt.Sprintf("%s-%s", testCase.Gopath, testCase.Path), func(t *testing.T) {
-			gopath := filepath.FromSlash(testCase.Gopath)
-			path := filepath.FromSlash(testCase.Path)
-
-			values, err := GetPackagePaths(gopath, path)
+		t.Run(testCase.Path, func(t *testing.T) {
+			values, err := GetPackagePaths("", testCase.Path)
 
 			if len(testCase.Expected) == 0 {
 				if err == nil {
@@ -78,15 +111,6 @@ func TestGetPackagePaths(t *testing.T) {
 	}
 }
 
-func TestUseVCSRootOption(t *testing.T) {
-	// Coverage only.
-	option := UseVCSRootOption("my/go/path")
-
-	if option == nil {
-		t.Fatal("expected not nil")
-	}
-}
-
 func TestGetPackageInfo(t *testing.T) {
 	info, err := GetPackageInfo("github.com/depbleed/go/go-depbleed")
 
@@ -101,6 +125,143 @@ func TestGetPackageInfo(t *testing.T) {
 	}
 }
 
+func TestGetPackageInfoModule(t *testing.T) {
+	info, err := GetPackageInfo("example.com/mod/sub", DirOption("./fixtures/_module"))
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if info.Module == nil {
+		t.Fatal("expected a module")
+	}
+
+	expected := "example.com/mod"
+
+	if info.Module.Path != expected {
+		t.Errorf("expected \"%s\", got \"%s\"", expected, info.Module.Path)
+	}
+
+	if leaks := info.Leaks(); len(leaks) != 0 {
+		t.Errorf("expected no leaks but got: %v", leaks)
+	}
+}
+
+func TestGetPackageInfos(t *testing.T) {
+	patterns := []string{
+		"github.com/depbleed/go/examples/exstruct",
+		"github.com/depbleed/go/examples/exmap",
+	}
+	infos, err := GetPackageInfos(patterns)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if len(infos) != len(patterns) {
+		t.Fatalf("expected %d packages, got %d", len(patterns), len(infos))
+	}
+
+	for _, info := range infos {
+		if info.Fset != infos[0].Fset {
+			t.Errorf("expected packages to share the same file set")
+		}
+	}
+}
+
+func TestGetPackageInfosNoPackages(t *testing.T) {
+	_, err := GetPackageInfos(nil, DirOption("./fixtures/gopath/src/foo/baz"))
+
+	if err == nil {
+		t.Error("expected an error but didn't get one")
+	}
+}
+
+func TestIncludeTestsOption(t *testing.T) {
+	testCases := []struct {
+		Options  []Option
+		Expected []string
+	}{
+		{
+			Expected: []string{
+				"github.com/depbleed/go/examples/extests.Value",
+			},
+		},
+		{
+			Options: []Option{IncludeTestsOption()},
+			Expected: []string{
+				"github.com/depbleed/go/examples/extests.Fixture",
+				"github.com/depbleed/go/examples/extests.Value",
+				"github.com/depbleed/go/examples/extests/testutil.Helper",
+				"github.com/depbleed/go/examples/extests_test.Helper",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		infos, err := GetPackageInfos([]string{"github.com/depbleed/go/examples/extests/..."}, testCase.Options...)
+
+		if err != nil {
+			t.Fatalf("expected no error but got: %s", err)
+		}
+
+		var values []string
+
+		for _, info := range infos {
+			for _, leak := range info.Leaks() {
+				values = append(values, leak.Object.Pkg().Path()+"."+leak.Object.Name())
+			}
+		}
+
+		sort.Strings(values)
+
+		if !reflect.DeepEqual(values, testCase.Expected) {
+			t.Errorf("expected \"%v\" but got \"%v\"", testCase.Expected, values)
+		}
+	}
+}
+
+func TestAllowPackagesOption(t *testing.T) {
+	info, err := GetPackageInfo(
+		"github.com/depbleed/go/examples/excomplete",
+		AllowPackagesOption("github.com/depbleed/go/examples/*/vendor/..."),
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if !info.IsAllowedPackage("github.com/depbleed/go/examples/excomplete/vendor/a") {
+		t.Error("expected vendored package to be allowed")
+	}
+
+	// Vendored copies are also allowed by their upstream path.
+	upstreamInfo, err := GetPackageInfo(
+		"github.com/depbleed/go/examples/excomplete",
+		AllowPackagesOption("a/..."),
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if leaks := upstreamInfo.Leaks(); len(leaks) != 1 {
+		t.Errorf("expected 1 leak but got: %v", leaks)
+	}
+
+	leaks := info.Leaks()
+
+	if len(leaks) != 1 {
+		t.Fatalf("expected 1 leak but got: %v", leaks)
+	}
+
+	expected := "github.com/depbleed/go/go-depbleed"
+
+	if leaks[0].PackagePath() != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, leaks[0].PackagePath())
+	}
+}
+
 type failOption struct{}
 
 func (failOption) apply(*PackageInfo) error { return errors.New("fail") }
@@ -143,6 +304,17 @@ func TestGetTypePackagePathNamedType(t *testing.T) {
 	}
 }
 
+func TestGetTypePackagePathTypeParam(t *testing.T) {
+	expected := ""
+	typename := types.NewTypeName(token.NoPos, nil, "T", nil)
+	v := types.NewTypeParam(typename, types.NewInterfaceType(nil, nil))
+	path := GetTypePackagePath(v)
+
+	if path != expected {
+		t.Errorf("expected \"%s\" got \"%s\"", expected, path)
+	}
+}
+
 func TestGetTypeShortName(t *testing.T) {
 	expected := "bar.MyType"
 	pkg := types.NewPackage("foo/bar", "bar")
@@ -155,6 +327,27 @@ func TestGetTypeShortName(t *testing.T) {
 	}
 }
 
+func TestGetTypeShortNameTypeArguments(t *testing.T) {
+	expected := "bar.Set[a.Int]"
+	pkg := types.NewPackage("foo/bar", "bar")
+	vendorPkg := types.NewPackage("foo/bar/vendor/a", "a")
+	param := types.NewTypeParam(types.NewTypeName(token.NoPos, pkg, "T", nil), types.NewInterfaceType(nil, nil))
+	set := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Set", nil), types.NewStruct(nil, nil), nil)
+	set.SetTypeParams([]*types.TypeParam{param})
+	arg := types.NewNamed(types.NewTypeName(token.NoPos, vendorPkg, "Int", nil), types.Typ[types.Int], nil)
+	v, err := types.Instantiate(nil, set, []types.Type{arg}, false)
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	shortName := GetTypeShortName(v)
+
+	if shortName != expected {
+		t.Errorf("expected \"%s\" got \"%s\"", expected, shortName)
+	}
+}
+
 func TestGetRoot(t *testing.T) {
 	info := PackageInfo{
 		Package: &types.Package{},
@@ -169,6 +362,20 @@ func TestGetRoot(t *testing.T) {
 	}
 }
 
+func TestGetRootWithModule(t *testing.T) {
+	info := PackageInfo{
+		Package: types.NewPackage("example.com/mod/sub", "sub"),
+		Module:  &Module{Path: "example.com/mod"},
+	}
+
+	expected := "example.com/mod"
+	value := info.GetRoot()
+
+	if value != expected {
+		t.Errorf("expected\"%s\", got: \"%s\"", expected, value)
+	}
+}
+
 func TestGetRootWithVCS(t *testing.T) {
 	info := PackageInfo{
 		Package: &types.Package{},
@@ -278,3 +485,124 @@ func TestIsSubPackage(t *testing.T) {
 		})
 	}
 }
+
+func TestIsSubPackageVendored(t *testing.T) {
+	rootPackage := "github.com/depbleed/go/examples/exconflict/vendor/lib-a"
+	testCases := []struct {
+		Package  string
+		Expected bool
+	}{
+		{
+			Package:  "github.com/depbleed/go/examples/exconflict/vendor/lib-a",
+			Expected: true,
+		},
+		{
+			Package:  "github.com/depbleed/go/examples/exconflict/vendor/lib-a/sub",
+			Expected: true,
+		},
+		{
+			Package:  "github.com/depbleed/go/examples/exconflict/vendor/lib-a/vendor/lib-foo",
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Package, func(t *testing.T) {
+			value := IsSubPackage(testCase.Package, rootPackage)
+
+			if value != testCase.Expected {
+				t.Errorf("expected %t but got %t", testCase.Expected, value)
+			}
+		})
+	}
+}
+
+func TestGetInternalScope(t *testing.T) {
+	testCases := []struct {
+		Package  string
+		Scope    string
+		Expected bool
+	}{
+		{
+			Package:  "foo/bar",
+			Expected: false,
+		},
+		{
+			Package:  "foo/internals/bar",
+			Expected: false,
+		},
+		{
+			Package:  "internal/bar",
+			Scope:    "",
+			Expected: true,
+		},
+		{
+			Package:  "foo/internal",
+			Scope:    "foo",
+			Expected: true,
+		},
+		{
+			Package:  "foo/internal/bar/internal/baz",
+			Scope:    "foo/internal/bar",
+			Expected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Package, func(t *testing.T) {
+			scope, ok := GetInternalScope(testCase.Package)
+
+			if ok != testCase.Expected {
+				t.Errorf("expected %t but got %t", testCase.Expected, ok)
+			}
+
+			if scope != testCase.Scope {
+				t.Errorf("expected \"%s\" but got \"%s\"", testCase.Scope, scope)
+			}
+		})
+	}
+}
+
+func TestIsImportablePackage(t *testing.T) {
+	testCases := []struct {
+		Package         string
+		ImportedPackage string
+		Expected        bool
+	}{
+		{
+			Package:         "foo/bar",
+			ImportedPackage: "foo",
+			Expected:        true,
+		},
+		{
+			Package:         "foo/internal/bar",
+			ImportedPackage: "foo/baz",
+			Expected:        false,
+		},
+		{
+			Package:         "foo/internal/bar",
+			ImportedPackage: "foo/internal/baz",
+			Expected:        true,
+		},
+		{
+			Package:         "foo/internal/bar",
+			ImportedPackage: "foo/baz/internal/qux",
+			Expected:        true,
+		},
+		{
+			Package:         "foo/baz/internal/bar",
+			ImportedPackage: "foo/internal/qux",
+			Expected:        false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Package+"-"+testCase.ImportedPackage, func(t *testing.T) {
+			value := IsImportablePackage(testCase.Package, testCase.ImportedPackage)
+
+			if value != testCase.Expected {
+				t.Errorf("expected %t but got %t", testCase.Expected, value)
+			}
+		})
+	}
+}
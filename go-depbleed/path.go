@@ -0,0 +1,223 @@
+package depbleed
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// StepKind is the kind of a step in a leak path.
+type StepKind int
+
+const (
+	// ParamStep goes from a function to one of its parameters.
+	ParamStep StepKind = iota
+	// ResultStep goes from a function to one of its results.
+	ResultStep
+	// MapKeyStep goes from a map to its key type.
+	MapKeyStep
+	// ElemStep goes from a channel, a pointer, an array, a slice or a map to
+	// its element type.
+	ElemStep
+	// FieldStep goes from a struct to one of its fields.
+	FieldStep
+	// EmbeddedFieldStep goes from a struct to one of its embedded fields.
+	EmbeddedFieldStep
+	// MethodStep goes from a type to one of its methods.
+	MethodStep
+	// PromotedMethodStep goes from a type to one of the methods promoted from
+	// its embedded fields.
+	PromotedMethodStep
+	// EmbeddedTypeStep goes from an interface to one of its embedded types.
+	EmbeddedTypeStep
+	// TypeArgumentStep goes from an instantiated generic type to one of its
+	// type arguments.
+	TypeArgumentStep
+	// ConstraintStep goes from a type parameter to its constraint.
+	ConstraintStep
+	// UnionTermStep goes from a union to one of its terms.
+	UnionTermStep
+	// UnderlyingStep goes from an unexported type to its underlying type.
+	UnderlyingStep
+)
+
+var stepKindNames = [...]string{
+	ParamStep:          "param",
+	ResultStep:         "result",
+	MapKeyStep:         "map-key",
+	ElemStep:           "elem",
+	FieldStep:          "field",
+	EmbeddedFieldStep:  "embedded-field",
+	MethodStep:         "method",
+	PromotedMethodStep: "promoted-method",
+	EmbeddedTypeStep:   "embedded-type",
+	TypeArgumentStep:   "type-argument",
+	ConstraintStep:     "constraint",
+	UnionTermStep:      "union-term",
+	UnderlyingStep:     "underlying",
+}
+
+// String returns the name of the step kind.
+func (k StepKind) String() string {
+	if k >= 0 && int(k) < len(stepKindNames) {
+		return stepKindNames[k]
+	}
+
+	return fmt.Sprintf("StepKind(%d)", int(k))
+}
+
+// Step is a step of a leak path, from a type to one of the types it is made
+// of.
+type Step struct {
+	// Kind is the kind of the step.
+	Kind StepKind
+	// Type is the type the step goes from.
+	Type types.Type
+	// Index is the index of the parameter, result, type argument or union
+	// term.
+	Index int
+	// Name is the name of the parameter, field, method or type parameter, or
+	// the name of the embedded type. It is empty for unnamed parameters.
+	Name string
+}
+
+// typeName returns the name of the named type the step goes from.
+func (s Step) typeName() string {
+	if named, ok := s.Type.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+
+	return GetTypeShortName(s.Type)
+}
+
+// ofUnexportedType returns a suffix for the steps that go from an unexported
+// named type.
+func (s Step) ofUnexportedType() string {
+	if _, ok := s.Type.(*types.Named); ok {
+		return fmt.Sprintf(" of unexported type %s", s.typeName())
+	}
+
+	return ""
+}
+
+// nameOrIndex returns the quoted name of the step, or its index if it has no
+// name.
+func (s Step) nameOrIndex() string {
+	if s.Name == "" {
+		return fmt.Sprint(s.Index)
+	}
+
+	return fmt.Sprintf("\"%s\"", s.Name)
+}
+
+// String returns a description of the step.
+func (s Step) String() string {
+	switch s.Kind {
+	case ParamStep:
+		return fmt.Sprintf("function argument %s is an external type", s.nameOrIndex())
+	case ResultStep:
+		return fmt.Sprintf("function result %s is an external type", s.nameOrIndex())
+	case MapKeyStep:
+		return "map key is an external type"
+	case ElemStep:
+		switch s.Type.(type) {
+		case *types.Chan:
+			return "channel of external type"
+		case *types.Pointer:
+			return "pointer to external type"
+		case *types.Array:
+			return "array item is an external type"
+		case *types.Map:
+			return "map value is an external type"
+		default:
+			return "slice item is an external type"
+		}
+	case FieldStep:
+		return fmt.Sprintf("field \"%s\"%s is an external type", s.Name, s.ofUnexportedType())
+	case EmbeddedFieldStep:
+		return fmt.Sprintf("embedded field \"%s\"%s is an external type", s.Name, s.ofUnexportedType())
+	case MethodStep:
+		return fmt.Sprintf("method \"%s\"%s has an external type", s.Name, s.ofUnexportedType())
+	case PromotedMethodStep:
+		return fmt.Sprintf("promoted method \"%s\" has an external type", s.Name)
+	case EmbeddedTypeStep:
+		return fmt.Sprintf("embedded type %s is an external type", s.Name)
+	case TypeArgumentStep:
+		return fmt.Sprintf("type argument %d of %s is an external type", s.Index, s.typeName())
+	case ConstraintStep:
+		return fmt.Sprintf("type parameter %s constraint is an external type", s.Name)
+	case UnionTermStep:
+		return fmt.Sprintf("union term %d is an external type", s.Index)
+	case UnderlyingStep:
+		return fmt.Sprintf("unexported type %s is an external type", s.typeName())
+	}
+
+	return s.Kind.String()
+}
+
+// LeakPath is the path from the type of a leaking object down to the
+// offending type.
+//
+// LeakPath implements error, so that it can be returned by CheckLeaks.
+type LeakPath struct {
+	// Steps are the steps from the type of the leaking object to the
+	// offending type.
+	Steps []Step
+	// Type is the offending type, that comes from another package.
+	Type types.Type
+	// PackagePath is the path of the package the offending type comes from.
+	PackagePath string
+	// Vendored indicates that the offending type comes from a vendored
+	// package.
+	Vendored bool
+	// Internal indicates that the offending type comes from an internal
+	// package that some importers of the leaking package cannot import.
+	Internal bool
+}
+
+// prepend adds a step at the beginning of the path.
+func (p *LeakPath) prepend(step Step) *LeakPath {
+	p.Steps = append([]Step{step}, p.Steps...)
+
+	return p
+}
+
+// describe describes the origin of the offending type.
+//
+// Internal packages are described as such even when they are vendored, like
+// Kind classifies them.
+func (p LeakPath) describe() string {
+	if p.Internal {
+		return fmt.Sprintf("is an internal type from %s", p.PackagePath)
+	}
+
+	if p.Vendored {
+		return fmt.Sprintf("is a vendorized type from %s", p.PackagePath)
+	}
+
+	return fmt.Sprintf("is a global type from %s", p.PackagePath)
+}
+
+// Reasons returns a description of every step of the path, followed by a
+// description of the offending type.
+func (p LeakPath) Reasons() (result []string) {
+	for _, step := range p.Steps {
+		result = append(result, step.String())
+	}
+
+	return append(result, fmt.Sprintf("%s %s", GetTypeShortName(p.Type), p.describe()))
+}
+
+// Error renders the path as a message.
+func (p LeakPath) Error() string {
+	reasons := p.Reasons()
+
+	// A type argument that is itself the offending type is described in one
+	// go, like "type argument 0 of Set is a vendorized type from ...".
+	if n := len(p.Steps); n > 0 && p.Steps[n-1].Kind == TypeArgumentStep {
+		step := p.Steps[n-1]
+		reasons = append(reasons[:n-1], fmt.Sprintf("type argument %d of %s %s", step.Index, step.typeName(), p.describe()))
+	}
+
+	return strings.Join(reasons, ": ")
+}
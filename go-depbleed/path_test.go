@@ -0,0 +1,135 @@
+package depbleed
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStepKindString(t *testing.T) {
+	if ParamStep.String() != "param" {
+		t.Errorf("expected \"param\" but got \"%s\"", ParamStep.String())
+	}
+
+	if StepKind(-1).String() != "StepKind(-1)" {
+		t.Errorf("expected \"StepKind(-1)\" but got \"%s\"", StepKind(-1).String())
+	}
+}
+
+func TestLeakPathError(t *testing.T) {
+	pkg := types.NewPackage("foo/bar", "bar")
+	vendorPkg := types.NewPackage("foo/bar/vendor/a", "a")
+	internalPkg := types.NewPackage("foo/bar/vendor/a/internal/b", "b")
+	typ := types.NewNamed(types.NewTypeName(token.NoPos, vendorPkg, "Int", nil), types.Typ[types.Int], nil)
+	internalTyp := types.NewNamed(types.NewTypeName(token.NoPos, internalPkg, "Bool", nil), types.Typ[types.Bool], nil)
+	set := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Set", nil), types.NewMap(typ, types.NewStruct(nil, nil)), nil)
+	params := types.NewTuple(types.NewParam(token.NoPos, pkg, "", types.NewSlice(set)))
+	signature := types.NewSignatureType(nil, nil, nil, params, nil, false)
+
+	testCases := []struct {
+		Name     string
+		Path     LeakPath
+		Expected string
+	}{
+		{
+			Name: "nested",
+			Path: LeakPath{
+				Steps: []Step{
+					{Kind: ParamStep, Type: signature},
+					{Kind: ElemStep, Type: params.At(0).Type()},
+				},
+				Type:        typ,
+				PackagePath: vendorPkg.Path(),
+				Vendored:    true,
+			},
+			Expected: "function argument 0 is an external type: slice item is an external type: a.Int is a vendorized type from foo/bar/vendor/a",
+		},
+		{
+			Name: "type argument",
+			Path: LeakPath{
+				Steps: []Step{
+					{Kind: FieldStep, Type: types.NewStruct(nil, nil), Name: "F"},
+					{Kind: TypeArgumentStep, Type: set, Index: 0},
+				},
+				Type:        typ,
+				PackagePath: vendorPkg.Path(),
+				Internal:    true,
+			},
+			Expected: "field \"F\" is an external type: type argument 0 of Set is an internal type from foo/bar/vendor/a",
+		},
+		{
+			Name: "unexported type",
+			Path: LeakPath{
+				Steps: []Step{
+					{Kind: MethodStep, Type: set, Name: "M"},
+				},
+				Type:        typ,
+				PackagePath: vendorPkg.Path(),
+			},
+			Expected: "method \"M\" of unexported type Set has an external type: a.Int is a global type from foo/bar/vendor/a",
+		},
+		{
+			Name: "vendored internal",
+			Path: LeakPath{
+				Type:        internalTyp,
+				PackagePath: internalPkg.Path(),
+				Vendored:    true,
+				Internal:    true,
+			},
+			Expected: "b.Bool is an internal type from foo/bar/vendor/a/internal/b",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			value := testCase.Path.Error()
+
+			if value != testCase.Expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", testCase.Expected, value)
+			}
+
+			// The message describes the origin the kind is derived from.
+			if kind := testCase.Path.Kind(); kind == InternalLeak && !strings.Contains(value, "is an internal type") {
+				t.Errorf("expected an internal type message for kind %s but got: %s", kind, value)
+			}
+		})
+	}
+}
+
+func TestCheckLeaksPath(t *testing.T) {
+	info, err := GetPackageInfo("github.com/depbleed/go/examples/exunexported")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	obj := info.Package.Scope().Lookup("New")
+	err = info.CheckLeaks(obj.Type())
+	path, ok := err.(*LeakPath)
+
+	if !ok {
+		t.Fatalf("expected a leak path but got: %v", err)
+	}
+
+	var kinds []StepKind
+
+	for _, step := range path.Steps {
+		kinds = append(kinds, step.Kind)
+	}
+
+	expected := []StepKind{ResultStep, ElemStep, FieldStep}
+
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected %v but got %v", expected, kinds)
+	}
+
+	if path.Steps[2].Name != "X" {
+		t.Errorf("expected field \"X\" but got \"%s\"", path.Steps[2].Name)
+	}
+
+	if GetTypeShortName(path.Type) != "a.Int" {
+		t.Errorf("expected a.Int but got %s", GetTypeShortName(path.Type))
+	}
+}
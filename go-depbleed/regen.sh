@@ -1,8 +1,19 @@
 #!/bin/bash
 
-GOROOT=`go env GOROOT`
-SRCROOT="$GOROOT/src"
-MODULES=`find $SRCROOT -name \*.go | xargs -L 1 dirname | sort -u | sed s\0^$SRCROOT/\0\0 | grep -v "^vendor/" | sed 's/^.*$/"&",/'`
+STD=`go list std | LC_ALL=C sort -u`
+MODULES=`echo "$STD" | sed 's/^.*$/"&",/'`
+
+# The API files of the toolchain list the exported API added by each release:
+# a package was added by the first release that lists it.
+API=`go env GOROOT`/api
+MINOR=`go env GOVERSION | sed 's/^go1\.\([0-9]*\).*$/\1/'`
+VERSIONS=`for minor in $(seq 1 $MINOR); do
+	sed -n 's/^pkg \([^ ,]*\).*$/\1 '$minor'/p' $API/go1.$minor.txt
+done | LC_ALL=C sort -k1,1 -k2,2n | awk '!seen[$1]++' | while read pkg minor; do
+	if echo "$STD" | grep -qx "$pkg" && ! grep -q "^pkg $pkg[ ,]" $API/go1.txt; then
+		echo "\"$pkg\": $minor,"
+	fi
+done`
 
 gofmt <<EOF >standard_go_packages.go
 //go:generate ./regen.sh
@@ -11,8 +22,22 @@ package depbleed
 
 // This list was auto-generated by running \`go generate\`.
 //
-// You should probably re-do it on every Go release and commit the result.
+// It is only used when the Go toolchain in use can't be queried. You should
+// probably re-do it on every Go release and commit the result.
 var standardGoPackages = []string{
 $MODULES
 }
+
+// standardGoPackagesMinor is the minor version of the Go release the standard
+// packages were listed for.
+const standardGoPackagesMinor = $MINOR
+
+// standardGoPackageMinors maps the standard packages that were added after Go
+// 1.0 to the minor version of the Go release that added them.
+//
+// Releases are the ones that added the first exported API of the packages, so
+// that packages without exported API, like \`time/tzdata\`, are not listed.
+var standardGoPackageMinors = map[string]int{
+$VERSIONS
+}
 EOF
@@ -0,0 +1,210 @@
+package depbleed
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	standardPackagesMutex sync.Mutex
+	standardPackagesCache = make(map[string][]string)
+
+	defaultStandardPackagesOnce sync.Once
+	defaultStandardPackages     []string
+)
+
+// GetStandardPackages returns the sorted list of standard packages provided
+// by the specified Go version, like `1.21.0`.
+//
+// If `version` is empty, the Go toolchain in use is queried. Otherwise, the
+// packages are derived from the releases that added them, as generated by `go
+// generate`, which works offline and for any release since Go 1.0. Releases
+// newer than the generated list can only be the one of the toolchain in use.
+func GetStandardPackages(version string) ([]string, error) {
+	if version == "" {
+		return listStandardPackages("")
+	}
+
+	minor, err := parseGoMinorVersion(version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if minor > standardGoPackagesMinor {
+		toolchainVersion, err := getToolchainVersion()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if toolchainMinor, err := parseGoMinorVersion(toolchainVersion); err != nil || toolchainMinor != minor {
+			return nil, fmt.Errorf("standard packages are only known up to Go 1.%d and the toolchain in use is %s: run `go generate` with a newer toolchain", standardGoPackagesMinor, toolchainVersion)
+		}
+
+		return listStandardPackages(version)
+	}
+
+	var packages []string
+
+	for _, p := range standardGoPackages {
+		if standardGoPackageMinors[p] <= minor {
+			packages = append(packages, p)
+		}
+	}
+
+	return packages, nil
+}
+
+// listStandardPackages lists the standard packages of the Go toolchain in use,
+// and caches them under the specified key.
+func listStandardPackages(key string) ([]string, error) {
+	standardPackagesMutex.Lock()
+	defer standardPackagesMutex.Unlock()
+
+	if packages, ok := standardPackagesCache[key]; ok {
+		return packages, nil
+	}
+
+	cmd := exec.Command("go", "list", "std")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list standard packages: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var packages []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			packages = append(packages, line)
+		}
+	}
+
+	sort.Strings(packages)
+	standardPackagesCache[key] = packages
+
+	return packages, nil
+}
+
+// getToolchainVersion returns the version of the Go toolchain in use, like
+// `go1.21.0`.
+func getToolchainVersion() (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("go", "env", "GOVERSION")
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot determine Go version: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// goVersionRegexp matches Go versions like `1.21`, `1.21.0`, `go1.21rc1` or
+// the `1` of Go 1.0.
+var goVersionRegexp = regexp.MustCompile(`^(?:go)?1(?:\.(\d+))?(?:\.\d+|(?:rc|beta)\d+)?$`)
+
+// parseGoMinorVersion returns the minor version of a Go version, like `21` for
+// `1.21.0`.
+func parseGoMinorVersion(version string) (int, error) {
+	match := goVersionRegexp.FindStringSubmatch(version)
+
+	if match == nil {
+		return 0, fmt.Errorf("invalid Go version \"%s\": expected a version like 1.21.0", version)
+	}
+
+	if match[1] == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(match[1])
+}
+
+// getDefaultStandardPackages returns the standard packages of the Go toolchain
+// in use.
+//
+// If the toolchain can't be queried, the list generated by `go generate` is
+// used instead.
+func getDefaultStandardPackages() []string {
+	defaultStandardPackagesOnce.Do(func() {
+		packages, err := GetStandardPackages("")
+
+		if err != nil {
+			packages = standardGoPackages
+		}
+
+		defaultStandardPackages = packages
+	})
+
+	return defaultStandardPackages
+}
+
+func isStandardPackage(p string, packages []string) bool {
+	index := sort.SearchStrings(packages, p)
+
+	if index < len(packages) {
+		return packages[index] == p
+	}
+
+	return false
+}
+
+// IsStandardPackage checks whether a given package is standard.
+//
+// Standard packages are provided with Go. The Go toolchain in use is queried
+// to get them.
+func IsStandardPackage(p string) bool {
+	return isStandardPackage(p, getDefaultStandardPackages())
+}
+
+// IsStandardPackage checks whether a given package is standard for the
+// package.
+//
+// Unless specific standard packages were set, this is the same as the
+// IsStandardPackage function.
+func (i PackageInfo) IsStandardPackage(p string) bool {
+	if i.StandardPackages != nil {
+		return isStandardPackage(p, i.StandardPackages)
+	}
+
+	return IsStandardPackage(p)
+}
+
+type goVersionOption struct {
+	version string
+}
+
+// GoVersionOption returns an option that uses the standard packages of the
+// specified Go version, like `1.21.0`, instead of the ones of the Go toolchain
+// in use.
+func GoVersionOption(version string) Option {
+	return goVersionOption{version: version}
+}
+
+func (o goVersionOption) apply(i *PackageInfo) error {
+	packages, err := GetStandardPackages(o.version)
+
+	if err != nil {
+		return fmt.Errorf("cannot get standard packages for Go %s: %s", o.version, err)
+	}
+
+	i.StandardPackages = packages
+
+	return nil
+}
@@ -4,199 +4,14 @@ package depbleed
 
 // This list was auto-generated by running `go generate`.
 //
-// You should probably re-do it on every Go release and commit the result.
+// It is only used when the Go toolchain in use can't be queried. You should
+// probably re-do it on every Go release and commit the result.
 var standardGoPackages = []string{
 	"archive/tar",
 	"archive/zip",
 	"bufio",
-	"builtin",
 	"bytes",
-	"cmd/addr2line",
-	"cmd/api",
-	"cmd/api/testdata/src/pkg/p1",
-	"cmd/api/testdata/src/pkg/p2",
-	"cmd/api/testdata/src/pkg/p3",
-	"cmd/asm",
-	"cmd/asm/internal/arch",
-	"cmd/asm/internal/asm",
-	"cmd/asm/internal/flags",
-	"cmd/asm/internal/lex",
-	"cmd/cgo",
-	"cmd/compile",
-	"cmd/compile/internal/amd64",
-	"cmd/compile/internal/arm",
-	"cmd/compile/internal/arm64",
-	"cmd/compile/internal/gc",
-	"cmd/compile/internal/gc/builtin",
-	"cmd/compile/internal/gc/testdata",
-	"cmd/compile/internal/gc/testdata/gen",
-	"cmd/compile/internal/mips",
-	"cmd/compile/internal/mips64",
-	"cmd/compile/internal/ppc64",
-	"cmd/compile/internal/s390x",
-	"cmd/compile/internal/ssa",
-	"cmd/compile/internal/ssa/gen",
-	"cmd/compile/internal/syntax",
-	"cmd/compile/internal/test",
-	"cmd/compile/internal/x86",
-	"cmd/cover",
-	"cmd/cover/testdata",
-	"cmd/dist",
-	"cmd/doc",
-	"cmd/doc/testdata",
-	"cmd/fix",
-	"cmd/go",
-	"cmd/go/testdata",
-	"cmd/go/testdata/generate",
-	"cmd/go/testdata/importcom",
-	"cmd/go/testdata/importcom/src/bad",
-	"cmd/go/testdata/importcom/src/conflict",
-	"cmd/go/testdata/importcom/src/works/x",
-	"cmd/go/testdata/importcom/src/wrongplace",
-	"cmd/go/testdata/local",
-	"cmd/go/testdata/local/easysub",
-	"cmd/go/testdata/local/sub",
-	"cmd/go/testdata/local/sub/sub",
-	"cmd/go/testdata/norunexample",
-	"cmd/go/testdata/rundir",
-	"cmd/go/testdata/rundir/sub",
-	"cmd/go/testdata/shadow/root1/src/foo",
-	"cmd/go/testdata/shadow/root1/src/math",
-	"cmd/go/testdata/shadow/root2/src/foo",
-	"cmd/go/testdata/src/badc",
-	"cmd/go/testdata/src/badpkg",
-	"cmd/go/testdata/src/badtest/badexec",
-	"cmd/go/testdata/src/badtest/badsyntax",
-	"cmd/go/testdata/src/badtest/badvar",
-	"cmd/go/testdata/src/benchfatal",
-	"cmd/go/testdata/src/canonical/a",
-	"cmd/go/testdata/src/canonical/a/vendor/c",
-	"cmd/go/testdata/src/canonical/b",
-	"cmd/go/testdata/src/canonical/d",
-	"cmd/go/testdata/src/cgocover",
-	"cmd/go/testdata/src/cgocover2",
-	"cmd/go/testdata/src/cgocover3",
-	"cmd/go/testdata/src/cgocover4",
-	"cmd/go/testdata/src/cgotest",
-	"cmd/go/testdata/src/dupload",
-	"cmd/go/testdata/src/dupload/p",
-	"cmd/go/testdata/src/dupload/p2",
-	"cmd/go/testdata/src/dupload/vendor/p",
-	"cmd/go/testdata/src/empty/pkg",
-	"cmd/go/testdata/src/empty/pkgtest",
-	"cmd/go/testdata/src/empty/pkgtestxtest",
-	"cmd/go/testdata/src/empty/pkgxtest",
-	"cmd/go/testdata/src/empty/test",
-	"cmd/go/testdata/src/empty/testxtest",
-	"cmd/go/testdata/src/empty/xtest",
-	"cmd/go/testdata/src/gencycle",
-	"cmd/go/testdata/src/go-cmd-test",
-	"cmd/go/testdata/src/importmain/ismain",
-	"cmd/go/testdata/src/importmain/test",
-	"cmd/go/testdata/src/main_test",
-	"cmd/go/testdata/src/my.pkg",
-	"cmd/go/testdata/src/my.pkg/main",
-	"cmd/go/testdata/src/notest",
-	"cmd/go/testdata/src/run",
-	"cmd/go/testdata/src/run/internal",
-	"cmd/go/testdata/src/run/subdir/internal/private",
-	"cmd/go/testdata/src/syntaxerror",
-	"cmd/go/testdata/src/testcycle/p1",
-	"cmd/go/testdata/src/testcycle/p2",
-	"cmd/go/testdata/src/testcycle/p3",
-	"cmd/go/testdata/src/testcycle/q1",
-	"cmd/go/testdata/src/testdep/p1",
-	"cmd/go/testdata/src/testdep/p2",
-	"cmd/go/testdata/src/testdep/p3",
-	"cmd/go/testdata/src/testrace",
-	"cmd/go/testdata/src/vend",
-	"cmd/go/testdata/src/vend/dir1",
-	"cmd/go/testdata/src/vend/hello",
-	"cmd/go/testdata/src/vend/subdir",
-	"cmd/go/testdata/src/vend/vendor/p",
-	"cmd/go/testdata/src/vend/vendor/q",
-	"cmd/go/testdata/src/vend/vendor/strings",
-	"cmd/go/testdata/src/vend/vendor/vend/dir1/dir2",
-	"cmd/go/testdata/src/vend/x",
-	"cmd/go/testdata/src/vend/x/invalid",
-	"cmd/go/testdata/src/vend/x/vendor/p",
-	"cmd/go/testdata/src/vend/x/vendor/p/p",
-	"cmd/go/testdata/src/vend/x/vendor/r",
-	"cmd/go/testdata/src/vetpkg",
-	"cmd/go/testdata/src/xtestonly",
-	"cmd/go/testdata/testimport",
-	"cmd/go/testdata/testimport/p1",
-	"cmd/go/testdata/testimport/p2",
-	"cmd/go/testdata/testinternal",
-	"cmd/go/testdata/testinternal2",
-	"cmd/go/testdata/testinternal2/x/y/z/internal/w",
-	"cmd/go/testdata/testinternal3",
-	"cmd/go/testdata/testinternal4/src/p",
-	"cmd/go/testdata/testinternal4/src/q/internal/x",
-	"cmd/go/testdata/testinternal4/src/q/j",
-	"cmd/go/testdata/testonly",
-	"cmd/go/testdata/testterminal18153",
-	"cmd/go/testdata/testvendor/src/p",
-	"cmd/go/testdata/testvendor/src/q/vendor/x",
-	"cmd/go/testdata/testvendor/src/q/y",
-	"cmd/go/testdata/testvendor/src/q/z",
-	"cmd/go/testdata/testvendor2/src/p",
-	"cmd/go/testdata/testvendor2/vendor/x",
-	"cmd/gofmt",
-	"cmd/internal/bio",
-	"cmd/internal/browser",
-	"cmd/internal/dwarf",
-	"cmd/internal/gcprog",
-	"cmd/internal/goobj",
-	"cmd/internal/obj",
-	"cmd/internal/obj/arm",
-	"cmd/internal/obj/arm64",
-	"cmd/internal/obj/mips",
-	"cmd/internal/obj/ppc64",
-	"cmd/internal/obj/s390x",
-	"cmd/internal/obj/x86",
-	"cmd/internal/objfile",
-	"cmd/internal/sys",
-	"cmd/link",
-	"cmd/link/internal/amd64",
-	"cmd/link/internal/arm",
-	"cmd/link/internal/arm64",
-	"cmd/link/internal/ld",
-	"cmd/link/internal/mips",
-	"cmd/link/internal/mips64",
-	"cmd/link/internal/ppc64",
-	"cmd/link/internal/s390x",
-	"cmd/link/internal/x86",
-	"cmd/nm",
-	"cmd/objdump",
-	"cmd/objdump/testdata",
-	"cmd/pack",
-	"cmd/pprof",
-	"cmd/pprof/internal/commands",
-	"cmd/pprof/internal/driver",
-	"cmd/pprof/internal/fetch",
-	"cmd/pprof/internal/plugin",
-	"cmd/pprof/internal/report",
-	"cmd/pprof/internal/svg",
-	"cmd/pprof/internal/symbolizer",
-	"cmd/pprof/internal/symbolz",
-	"cmd/pprof/internal/tempfile",
-	"cmd/trace",
-	"cmd/vendor/golang.org/x/arch/arm/armasm",
-	"cmd/vendor/golang.org/x/arch/ppc64/ppc64asm",
-	"cmd/vendor/golang.org/x/arch/x86/x86asm",
-	"cmd/vet",
-	"cmd/vet/all",
-	"cmd/vet/internal/cfg",
-	"cmd/vet/internal/whitelist",
-	"cmd/vet/testdata",
-	"cmd/vet/testdata/asm",
-	"cmd/vet/testdata/buildtag",
-	"cmd/vet/testdata/cgo",
-	"cmd/vet/testdata/divergent",
-	"cmd/vet/testdata/incomplete",
-	"cmd/vet/testdata/tagtest",
-	"cmd/vet/testdata/testingpkg",
+	"cmp",
 	"compress/bzip2",
 	"compress/flate",
 	"compress/gzip",
@@ -211,29 +26,95 @@ var standardGoPackages = []string{
 	"crypto/cipher",
 	"crypto/des",
 	"crypto/dsa",
+	"crypto/ecdh",
 	"crypto/ecdsa",
+	"crypto/ed25519",
 	"crypto/elliptic",
+	"crypto/fips140",
+	"crypto/hkdf",
 	"crypto/hmac",
-	"crypto/internal/cipherhw",
+	"crypto/hpke",
+	"crypto/internal/boring",
+	"crypto/internal/boring/bbig",
+	"crypto/internal/boring/bcache",
+	"crypto/internal/boring/sig",
+	"crypto/internal/constanttime",
+	"crypto/internal/cryptotest",
+	"crypto/internal/cryptotest/wycheproof",
+	"crypto/internal/cryptotest/x509limbo",
+	"crypto/internal/entropy",
+	"crypto/internal/entropy/v1.0.0",
+	"crypto/internal/fips140",
+	"crypto/internal/fips140/aes",
+	"crypto/internal/fips140/aes/gcm",
+	"crypto/internal/fips140/alias",
+	"crypto/internal/fips140/bigmod",
+	"crypto/internal/fips140/check",
+	"crypto/internal/fips140/check/checktest",
+	"crypto/internal/fips140/drbg",
+	"crypto/internal/fips140/ecdh",
+	"crypto/internal/fips140/ecdsa",
+	"crypto/internal/fips140/ed25519",
+	"crypto/internal/fips140/edwards25519",
+	"crypto/internal/fips140/edwards25519/field",
+	"crypto/internal/fips140/hkdf",
+	"crypto/internal/fips140/hmac",
+	"crypto/internal/fips140/mldsa",
+	"crypto/internal/fips140/mlkem",
+	"crypto/internal/fips140/nistec",
+	"crypto/internal/fips140/nistec/fiat",
+	"crypto/internal/fips140/pbkdf2",
+	"crypto/internal/fips140/rsa",
+	"crypto/internal/fips140/sha256",
+	"crypto/internal/fips140/sha3",
+	"crypto/internal/fips140/sha512",
+	"crypto/internal/fips140/ssh",
+	"crypto/internal/fips140/subtle",
+	"crypto/internal/fips140/tls12",
+	"crypto/internal/fips140/tls13",
+	"crypto/internal/fips140cache",
+	"crypto/internal/fips140deps",
+	"crypto/internal/fips140deps/byteorder",
+	"crypto/internal/fips140deps/cpu",
+	"crypto/internal/fips140deps/godebug",
+	"crypto/internal/fips140deps/time",
+	"crypto/internal/fips140hash",
+	"crypto/internal/fips140only",
+	"crypto/internal/fips140test",
+	"crypto/internal/impl",
+	"crypto/internal/rand",
+	"crypto/internal/randutil",
+	"crypto/internal/sysrand",
+	"crypto/internal/sysrand/internal/seccomp",
 	"crypto/md5",
+	"crypto/mldsa",
+	"crypto/mlkem",
+	"crypto/mlkem/mlkemtest",
+	"crypto/pbkdf2",
 	"crypto/rand",
 	"crypto/rc4",
 	"crypto/rsa",
 	"crypto/sha1",
 	"crypto/sha256",
+	"crypto/sha3",
 	"crypto/sha512",
 	"crypto/subtle",
 	"crypto/tls",
+	"crypto/tls/internal/fips140tls",
 	"crypto/x509",
 	"crypto/x509/pkix",
 	"database/sql",
 	"database/sql/driver",
+	"database/sql/internal",
+	"debug/buildinfo",
 	"debug/dwarf",
 	"debug/elf",
 	"debug/gosym",
 	"debug/macho",
 	"debug/pe",
 	"debug/plan9obj",
+	"embed",
+	"embed/internal/embedtest",
 	"encoding",
 	"encoding/ascii85",
 	"encoding/asn1",
@@ -244,6 +125,13 @@ var standardGoPackages = []string{
 	"encoding/gob",
 	"encoding/hex",
 	"encoding/json",
+	"encoding/json/internal",
+	"encoding/json/internal/jsonflags",
+	"encoding/json/internal/jsonopts",
+	"encoding/json/internal/jsontest",
+	"encoding/json/internal/jsonwire",
+	"encoding/json/jsontext",
+	"encoding/json/v2",
 	"encoding/pem",
 	"encoding/xml",
 	"errors",
@@ -252,30 +140,27 @@ var standardGoPackages = []string{
 	"fmt",
 	"go/ast",
 	"go/build",
-	"go/build/testdata/multi",
-	"go/build/testdata/other",
-	"go/build/testdata/other/file",
+	"go/build/constraint",
 	"go/constant",
 	"go/doc",
-	"go/doc/testdata",
+	"go/doc/comment",
 	"go/format",
 	"go/importer",
 	"go/internal/gccgoimporter",
-	"go/internal/gccgoimporter/testdata",
 	"go/internal/gcimporter",
-	"go/internal/gcimporter/testdata",
-	"go/internal/gcimporter/testdata/versions",
+	"go/internal/srcimporter",
 	"go/parser",
 	"go/printer",
-	"go/printer/testdata",
 	"go/scanner",
 	"go/token",
 	"go/types",
+	"go/version",
 	"hash",
 	"hash/adler32",
 	"hash/crc32",
 	"hash/crc64",
 	"hash/fnv",
+	"hash/maphash",
 	"html",
 	"html/template",
 	"image",
@@ -287,24 +172,123 @@ var standardGoPackages = []string{
 	"image/jpeg",
 	"image/png",
 	"index/suffixarray",
+	"internal/abi",
+	"internal/asan",
+	"internal/bisect",
+	"internal/buildcfg",
+	"internal/bytealg",
+	"internal/byteorder",
+	"internal/cfg",
+	"internal/cgrouptest",
+	"internal/chacha8rand",
+	"internal/copyright",
+	"internal/coverage",
+	"internal/coverage/calloc",
+	"internal/coverage/cfile",
+	"internal/coverage/cformat",
+	"internal/coverage/cmerge",
+	"internal/coverage/decodecounter",
+	"internal/coverage/decodemeta",
+	"internal/coverage/encodecounter",
+	"internal/coverage/encodemeta",
+	"internal/coverage/pods",
+	"internal/coverage/rtcov",
+	"internal/coverage/slicereader",
+	"internal/coverage/slicewriter",
+	"internal/coverage/stringtab",
+	"internal/coverage/test",
+	"internal/coverage/uleb128",
+	"internal/cpu",
+	"internal/dag",
+	"internal/diff",
+	"internal/exportdata",
+	"internal/filepathlite",
+	"internal/fmtsort",
+	"internal/fuzz",
+	"internal/gate",
+	"internal/goarch",
+	"internal/godebug",
+	"internal/godebugs",
+	"internal/goexperiment",
+	"internal/goos",
+	"internal/goroot",
+	"internal/gover",
+	"internal/goversion",
+	"internal/lazyregexp",
+	"internal/lazytemplate",
+	"internal/msan",
+	"internal/nettest",
 	"internal/nettrace",
-	"internal/pprof/profile",
+	"internal/obscuretestdata",
+	"internal/oserror",
+	"internal/pkgbits",
+	"internal/platform",
+	"internal/poll",
+	"internal/profile",
+	"internal/profilerecord",
 	"internal/race",
+	"internal/reflectlite",
+	"internal/runtime/atomic",
+	"internal/runtime/cgobench",
+	"internal/runtime/cgroup",
+	"internal/runtime/exithook",
+	"internal/runtime/gc",
+	"internal/runtime/gc/internal/gen",
+	"internal/runtime/gc/scan",
+	"internal/runtime/maps",
+	"internal/runtime/math",
+	"internal/runtime/pprof/label",
+	"internal/runtime/startlinetest",
+	"internal/runtime/sys",
+	"internal/runtime/syscall/linux",
+	"internal/runtime/wasitest",
+	"internal/saferio",
 	"internal/singleflight",
+	"internal/strconv",
+	"internal/stringslite",
+	"internal/sync",
+	"internal/synctest",
+	"internal/syscall/execenv",
 	"internal/syscall/unix",
-	"internal/syscall/windows",
-	"internal/syscall/windows/registry",
-	"internal/syscall/windows/sysdll",
+	"internal/sysinfo",
+	"internal/syslist",
 	"internal/testenv",
+	"internal/testhash",
+	"internal/testlog",
+	"internal/testpty",
 	"internal/trace",
+	"internal/trace/internal/testgen",
+	"internal/trace/internal/tracev1",
+	"internal/trace/raw",
+	"internal/trace/testtrace",
+	"internal/trace/tracev2",
+	"internal/trace/traceviewer",
+	"internal/trace/traceviewer/format",
+	"internal/trace/version",
+	"internal/txtar",
+	"internal/types/errors",
+	"internal/unsafeheader",
+	"internal/xcoff",
+	"internal/zstd",
 	"io",
+	"io/fs",
 	"io/ioutil",
+	"iter",
 	"log",
+	"log/internal",
+	"log/slog",
+	"log/slog/internal",
+	"log/slog/internal/benchmarks",
+	"log/slog/internal/buffer",
 	"log/syslog",
+	"maps",
 	"math",
 	"math/big",
+	"math/big/internal/asmgen",
+	"math/bits",
 	"math/cmplx",
 	"math/rand",
+	"math/rand/v2",
 	"mime",
 	"mime/multipart",
 	"mime/quotedprintable",
@@ -317,9 +301,16 @@ var standardGoPackages = []string{
 	"net/http/httptrace",
 	"net/http/httputil",
 	"net/http/internal",
+	"net/http/internal/ascii",
+	"net/http/internal/http2",
+	"net/http/internal/httpcommon",
+	"net/http/internal/httpsfv",
+	"net/http/internal/testcert",
 	"net/http/pprof",
+	"net/internal/cgotest",
 	"net/internal/socktest",
 	"net/mail",
+	"net/netip",
 	"net/rpc",
 	"net/rpc/jsonrpc",
 	"net/smtp",
@@ -327,46 +318,139 @@ var standardGoPackages = []string{
 	"net/url",
 	"os",
 	"os/exec",
+	"os/exec/internal/fdtest",
 	"os/signal",
 	"os/user",
 	"path",
 	"path/filepath",
 	"plugin",
 	"reflect",
+	"reflect/internal/example1",
+	"reflect/internal/example2",
 	"regexp",
 	"regexp/syntax",
 	"runtime",
 	"runtime/cgo",
+	"runtime/coverage",
 	"runtime/debug",
-	"runtime/internal/atomic",
-	"runtime/internal/sys",
-	"runtime/msan",
+	"runtime/metrics",
 	"runtime/pprof",
-	"runtime/pprof/internal/protopprof",
 	"runtime/race",
-	"runtime/race/testdata",
-	"runtime/testdata/testprog",
-	"runtime/testdata/testprogcgo",
-	"runtime/testdata/testprogcgo/windows",
-	"runtime/testdata/testprognet",
+	"runtime/race/internal/amd64v1",
 	"runtime/trace",
+	"slices",
 	"sort",
 	"strconv",
 	"strings",
+	"structs",
 	"sync",
 	"sync/atomic",
 	"syscall",
 	"testing",
+	"testing/cryptotest",
+	"testing/fstest",
 	"testing/internal/testdeps",
 	"testing/iotest",
 	"testing/quick",
+	"testing/slogtest",
+	"testing/synctest",
 	"text/scanner",
 	"text/tabwriter",
 	"text/template",
 	"text/template/parse",
 	"time",
+	"time/tzdata",
 	"unicode",
 	"unicode/utf16",
 	"unicode/utf8",
+	"unique",
 	"unsafe",
+	"uuid",
+	"vendor/golang.org/x/crypto/chacha20",
+	"vendor/golang.org/x/crypto/chacha20poly1305",
+	"vendor/golang.org/x/crypto/cryptobyte",
+	"vendor/golang.org/x/crypto/cryptobyte/asn1",
+	"vendor/golang.org/x/crypto/hkdf",
+	"vendor/golang.org/x/crypto/internal/alias",
+	"vendor/golang.org/x/crypto/internal/poly1305",
+	"vendor/golang.org/x/net/dns/dnsmessage",
+	"vendor/golang.org/x/net/http/httpguts",
+	"vendor/golang.org/x/net/http/httpproxy",
+	"vendor/golang.org/x/net/http2/hpack",
+	"vendor/golang.org/x/net/http3",
+	"vendor/golang.org/x/net/idna",
+	"vendor/golang.org/x/net/internal/http3",
+	"vendor/golang.org/x/net/internal/httpcommon",
+	"vendor/golang.org/x/net/internal/quic/quicwire",
+	"vendor/golang.org/x/net/nettest",
+	"vendor/golang.org/x/net/quic",
+	"vendor/golang.org/x/sys/cpu",
+	"vendor/golang.org/x/text/secure/bidirule",
+	"vendor/golang.org/x/text/transform",
+	"vendor/golang.org/x/text/unicode/bidi",
+	"vendor/golang.org/x/text/unicode/norm",
+	"weak",
+}
+
+// standardGoPackagesMinor is the minor version of the Go release the standard
+// packages were listed for.
+const standardGoPackagesMinor = 27
+
+// standardGoPackageMinors maps the standard packages that were added after Go
+// 1.0 to the minor version of the Go release that added them.
+//
+// Releases are the ones that added the first exported API of the packages, so
+// that packages without exported API, like `time/tzdata`, are not listed.
+var standardGoPackageMinors = map[string]int{
+	"cmp":                    21,
+	"context":                7,
+	"crypto/ecdh":            20,
+	"crypto/ed25519":         13,
+	"crypto/fips140":         24,
+	"crypto/hkdf":            24,
+	"crypto/hpke":            26,
+	"crypto/mldsa":           27,
+	"crypto/mlkem":           24,
+	"crypto/mlkem/mlkemtest": 26,
+	"crypto/pbkdf2":          24,
+	"crypto/sha3":            24,
+	"debug/buildinfo":        18,
+	"debug/plan9obj":         3,
+	"embed":                  16,
+	"encoding":               2,
+	"encoding/json/jsontext": 27,
+	"encoding/json/v2":       27,
+	"go/build/constraint":    16,
+	"go/constant":            5,
+	"go/doc/comment":         19,
+	"go/format":              1,
+	"go/importer":            5,
+	"go/types":               5,
+	"go/version":             22,
+	"hash/maphash":           14,
+	"image/color/palette":    2,
+	"io/fs":                  16,
+	"iter":                   23,
+	"log/slog":               21,
+	"maps":                   21,
+	"math/bits":              9,
+	"math/rand/v2":           22,
+	"mime/quotedprintable":   5,
+	"net/http/cookiejar":     1,
+	"net/http/httptrace":     7,
+	"net/netip":              18,
+	"plugin":                 8,
+	"runtime/cgo":            17,
+	"runtime/coverage":       20,
+	"runtime/metrics":        16,
+	"runtime/trace":          5,
+	"slices":                 21,
+	"structs":                23,
+	"testing/cryptotest":     26,
+	"testing/fstest":         16,
+	"testing/slogtest":       21,
+	"testing/synctest":       25,
+	"unique":                 23,
+	"uuid":                   27,
+	"weak":                   24,
 }
@@ -0,0 +1,115 @@
+package depbleed
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestGetStandardPackages(t *testing.T) {
+	packages, err := GetStandardPackages("")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if !sort.StringsAreSorted(packages) {
+		t.Error("expected standard packages to be sorted")
+	}
+
+	if !isStandardPackage("fmt", packages) {
+		t.Error("expected fmt to be a standard package")
+	}
+
+	if isStandardPackage("cmd/go", packages) {
+		t.Error("expected cmd/go not to be a standard package")
+	}
+}
+
+func TestGetStandardPackagesVersion(t *testing.T) {
+	testCases := []struct {
+		Version  string
+		Standard []string
+		Other    []string
+	}{
+		{
+			Version:  "1.20.0",
+			Standard: []string{"fmt", "net/netip", "crypto/ecdh"},
+			Other:    []string{"slices", "log/slog", "iter"},
+		},
+		{
+			Version:  "go1.21rc1",
+			Standard: []string{"slices", "log/slog"},
+			Other:    []string{"iter"},
+		},
+		{
+			Version:  "1",
+			Standard: []string{"fmt", "net/http"},
+			Other:    []string{"context", "go/types"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Version, func(t *testing.T) {
+			packages, err := GetStandardPackages(testCase.Version)
+
+			if err != nil {
+				t.Fatalf("expected no error but got: %s", err)
+			}
+
+			if !sort.StringsAreSorted(packages) {
+				t.Error("expected standard packages to be sorted")
+			}
+
+			for _, p := range testCase.Standard {
+				if !isStandardPackage(p, packages) {
+					t.Errorf("expected %s to be a standard package", p)
+				}
+			}
+
+			for _, p := range testCase.Other {
+				if isStandardPackage(p, packages) {
+					t.Errorf("expected %s not to be a standard package", p)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStandardPackagesUnknownVersion(t *testing.T) {
+	if _, err := GetStandardPackages(fmt.Sprintf("1.%d.0", standardGoPackagesMinor+100)); err == nil {
+		t.Error("expected an error but didn't get one")
+	}
+}
+
+func TestStandardGoPackagesSorted(t *testing.T) {
+	if !sort.StringsAreSorted(standardGoPackages) {
+		t.Error("expected the generated standard packages to be sorted")
+	}
+}
+
+func TestGoVersionOptionInvalidVersion(t *testing.T) {
+	var info PackageInfo
+
+	if err := GoVersionOption("invalid").apply(&info); err == nil {
+		t.Error("expected an error but didn't get one")
+	}
+}
+
+func TestPackageInfoIsStandardPackage(t *testing.T) {
+	info := PackageInfo{
+		StandardPackages: []string{"fmt", "slices"},
+	}
+
+	if !info.IsStandardPackage("slices") {
+		t.Error("expected slices to be a standard package")
+	}
+
+	if info.IsStandardPackage("net/http") {
+		t.Error("expected net/http not to be a standard package")
+	}
+
+	if !(PackageInfo{}).IsStandardPackage("net/http") {
+		t.Error("expected net/http to be a standard package by default")
+	}
+}
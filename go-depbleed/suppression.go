@@ -0,0 +1,189 @@
+package depbleed
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// suppressionDirective is the comment directive that suppresses the leak of
+// the object declared next to it.
+const suppressionDirective = "//depbleed:ignore"
+
+// Suppression represents a `//depbleed:ignore [reason]` directive.
+//
+// A directive applies to the objects declared by the declaration, field or
+// method it documents, or that it follows on the same line. On a type
+// declaration, it also applies to the fields and interface methods declared in
+// it, but not to the methods declared on the type.
+type Suppression struct {
+	Position token.Position
+	Reason   string
+	pos      token.Pos
+	// objects are the positions of the objects the directive applies to.
+	objects []token.Pos
+}
+
+// Suppressions represents a slice of Suppression instances.
+type Suppressions []Suppression
+
+// parseSuppression parses a suppression directive from a comment, if it is
+// one.
+func parseSuppression(comment *ast.Comment) (reason string, ok bool) {
+	if !strings.HasPrefix(comment.Text, suppressionDirective) {
+		return "", false
+	}
+
+	rest := comment.Text[len(suppressionDirective):]
+
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+
+	return strings.TrimSpace(rest), true
+}
+
+// GetSuppressions returns the suppression directives in the package.
+func (i PackageInfo) GetSuppressions() (result Suppressions) {
+	add := func(objects []token.Pos, groups ...*ast.CommentGroup) {
+		for _, group := range groups {
+			if group == nil {
+				continue
+			}
+
+			for _, comment := range group.List {
+				if reason, ok := parseSuppression(comment); ok {
+					result = append(result, Suppression{
+						Position: i.Fset.Position(comment.Pos()),
+						Reason:   reason,
+						pos:      comment.Pos(),
+						objects:  objects,
+					})
+				}
+			}
+		}
+	}
+
+	for _, file := range i.Files {
+		if !i.reports(file.Pos()) {
+			continue
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				add([]token.Pos{node.Name.Pos()}, node.Doc)
+			case *ast.GenDecl:
+				var objects []token.Pos
+
+				for _, spec := range node.Specs {
+					objects = append(objects, specObjects(spec)...)
+				}
+
+				add(objects, node.Doc)
+			case *ast.ValueSpec:
+				add(specObjects(node), node.Doc, node.Comment)
+			case *ast.TypeSpec:
+				add(specObjects(node), node.Doc, node.Comment)
+			case *ast.Field:
+				add(fieldObjects(node), node.Doc, node.Comment)
+			}
+
+			return true
+		})
+	}
+
+	sort.Sort(result)
+
+	return
+}
+
+func specObjects(spec ast.Spec) (result []token.Pos) {
+	switch spec := spec.(type) {
+	case *ast.ValueSpec:
+		for _, name := range spec.Names {
+			result = append(result, name.Pos())
+		}
+	case *ast.TypeSpec:
+		result = append(result, spec.Name.Pos())
+
+		ast.Inspect(spec.Type, func(node ast.Node) bool {
+			var fields *ast.FieldList
+
+			switch node := node.(type) {
+			case *ast.StructType:
+				fields = node.Fields
+			case *ast.InterfaceType:
+				fields = node.Methods
+			}
+
+			if fields != nil {
+				for _, field := range fields.List {
+					result = append(result, fieldObjects(field)...)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return
+}
+
+func fieldObjects(field *ast.Field) (result []token.Pos) {
+	for _, name := range field.Names {
+		result = append(result, name.Pos())
+	}
+
+	// Embedded fields are named after their type.
+	if len(field.Names) == 0 {
+		typ := field.Type
+
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+
+		switch typ := typ.(type) {
+		case *ast.Ident:
+			result = append(result, typ.Pos())
+		case *ast.SelectorExpr:
+			result = append(result, typ.Sel.Pos())
+		}
+	}
+
+	return
+}
+
+// suppress checks whether any of the suppressions applies to any of the
+// specified leaks.
+func (slice Suppressions) suppress(leaks ...Leak) bool {
+	for _, suppression := range slice {
+		for _, pos := range suppression.objects {
+			for _, leak := range leaks {
+				if pos == leak.Object.Pos() {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// Len gives the length of the suppression slice.
+func (slice Suppressions) Len() int {
+	return len(slice)
+}
+
+// Less returns if slice[i] should be before slice[j].
+func (slice Suppressions) Less(i, j int) bool {
+	return slice[i].Position.Filename < slice[j].Position.Filename ||
+		(slice[i].Position.Filename == slice[j].Position.Filename &&
+			slice[i].Position.Offset < slice[j].Position.Offset)
+}
+
+// Swap swaps two elements.
+func (slice Suppressions) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
@@ -0,0 +1,74 @@
+package depbleed
+
+import (
+	"go/ast"
+	"testing"
+)
+
+func TestParseSuppression(t *testing.T) {
+	testCases := []struct {
+		Text     string
+		Reason   string
+		Expected bool
+	}{
+		{
+			Text:     "//depbleed:ignore",
+			Expected: true,
+		},
+		{
+			Text:     "//depbleed:ignore  part of the API ",
+			Reason:   "part of the API",
+			Expected: true,
+		},
+		{
+			Text:     "//depbleed:ignored",
+			Expected: false,
+		},
+		{
+			Text:     "// depbleed:ignore",
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Text, func(t *testing.T) {
+			reason, ok := parseSuppression(&ast.Comment{Text: testCase.Text})
+
+			if ok != testCase.Expected {
+				t.Errorf("expected %t but got %t", testCase.Expected, ok)
+			}
+
+			if reason != testCase.Reason {
+				t.Errorf("expected \"%s\" but got \"%s\"", testCase.Reason, reason)
+			}
+		})
+	}
+}
+
+func TestUnusedSuppressions(t *testing.T) {
+	packageInfo, err := GetPackageInfo("github.com/depbleed/go/examples/exignore")
+
+	if err != nil {
+		t.Fatalf("expected no error but got: %s", err)
+	}
+
+	if suppressions := packageInfo.GetSuppressions(); len(suppressions) != 5 {
+		t.Errorf("expected 5 suppressions but got %d", len(suppressions))
+	}
+
+	suppressions := packageInfo.UnusedSuppressions()
+
+	if len(suppressions) != 1 {
+		t.Fatalf("expected 1 unused suppression but got %d", len(suppressions))
+	}
+
+	expected := "a.Int was part of the API on purpose."
+
+	if suppressions[0].Reason != expected {
+		t.Errorf("expected \"%s\" but got \"%s\"", expected, suppressions[0].Reason)
+	}
+
+	if leaks := packageInfo.AllLeaks(); len(leaks) != 7 {
+		t.Errorf("expected 7 leaks but got %d", len(leaks))
+	}
+}